@@ -0,0 +1,474 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const querybuilderPath = "little-orm/internal/database/querybuilder"
+
+type model struct {
+	Name    string
+	Fields  []field
+	imports map[string]string // package name -> import path used by field types
+}
+
+type field struct {
+	// Name is the Go field name the registry knows the column by: promoted fields of
+	// embedded structs keep their name, fields of inline structs are qualified (Addr.City)
+	Name string
+	Type string
+}
+
+// handleName is the name of the handle of a field in the generated struct, e.g. AddrCity
+func (f field) handleName() string {
+	return strings.ReplaceAll(f.Name, ".", "")
+}
+
+// structDecl is a struct type declaration, with what is needed to qualify its field types
+type structDecl struct {
+	st      *ast.StructType
+	pkgName string            // name of the declaring package
+	pkgPath string            // import path of the declaring package, "" for the model package
+	imports map[string]string // imports of the declaring file, package name -> import path
+}
+
+// parseModels collects the structs of the package in dir that have db-tagged fields.
+// With untagged, exported fields without a db tag are columns too, as with
+// registry.MapUntaggedFields, so every exported struct with such fields is a model.
+// Embedded and inline structs are flattened as the registry does.
+func parseModels(dir string, only []string, untagged bool) ([]model, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+			names = append(names, filepath.Join(dir, name))
+		}
+	}
+
+	r := &resolver{fset: token.NewFileSet(), dir: dir, pkgs: make(map[string]map[string]structDecl)}
+	files, err := r.parseFiles(names)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+	r.pkgs[""] = r.structDecls(files, "")
+
+	var wanted map[string]bool
+	if len(only) > 0 {
+		wanted = make(map[string]bool, len(only))
+		for _, n := range only {
+			wanted[strings.TrimSpace(n)] = true
+		}
+	}
+
+	var models []model
+	for _, file := range files {
+		for _, ts := range typeSpecs(file) {
+			decl, ok := r.pkgs[""][ts.Name.Name]
+			if !ok || !ts.Name.IsExported() || (wanted != nil && !wanted[ts.Name.Name]) {
+				continue
+			}
+			m, err := r.model(ts.Name.Name, decl, untagged)
+			if err != nil {
+				return nil, err
+			}
+			if len(m.Fields) > 0 {
+				models = append(models, m)
+			}
+		}
+	}
+
+	sort.Slice(models, func(i, j int) bool { return models[i].Name < models[j].Name })
+	return models, nil
+}
+
+// resolver finds the declarations of the structs that models embed or inline, in the
+// model package or in the packages it imports
+type resolver struct {
+	fset *token.FileSet
+	dir  string
+	// pkgs holds the struct declarations of each package by type name, keyed by import
+	// path ("" for the model package)
+	pkgs map[string]map[string]structDecl
+}
+
+func (r *resolver) parseFiles(names []string) ([]*ast.File, error) {
+	var files []*ast.File
+	for _, name := range names {
+		file, err := parser.ParseFile(r.fset, name, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// structDecls indexes the non-generic struct types declared in the files of a package
+func (r *resolver) structDecls(files []*ast.File, pkgPath string) map[string]structDecl {
+	decls := make(map[string]structDecl)
+	for _, file := range files {
+		imports := fileImports(file)
+		for _, ts := range typeSpecs(file) {
+			if st, ok := ts.Type.(*ast.StructType); ok && ts.TypeParams == nil {
+				decls[ts.Name.Name] = structDecl{st: st, pkgName: file.Name.Name, pkgPath: pkgPath, imports: imports}
+			}
+		}
+	}
+	return decls
+}
+
+// resolve returns the declaration of the struct type typ, written in decl's file.
+// ok is false for types that aren't structs declared in Go source, e.g. int or a
+// type parameter.
+func (r *resolver) resolve(decl structDecl, typ ast.Expr) (target structDecl, ok bool, err error) {
+	if star, isStar := typ.(*ast.StarExpr); isStar {
+		typ = star.X
+	}
+	switch t := typ.(type) {
+	case *ast.Ident:
+		target, ok = r.pkgs[decl.pkgPath][t.Name]
+		return target, ok, nil
+	case *ast.SelectorExpr:
+		x, isIdent := t.X.(*ast.Ident)
+		if !isIdent || decl.imports[x.Name] == "" {
+			return structDecl{}, false, nil
+		}
+		decls, err := r.load(decl.imports[x.Name])
+		if err != nil {
+			return structDecl{}, false, err
+		}
+		target, ok = decls[t.Sel.Name]
+		return target, ok, nil
+	}
+	return structDecl{}, false, nil
+}
+
+// load parses the imported package at path, located with go/build from the model's directory
+func (r *resolver) load(path string) (map[string]structDecl, error) {
+	if decls, ok := r.pkgs[path]; ok {
+		return decls, nil
+	}
+	pkg, err := build.Import(path, r.dir, 0)
+	if err != nil {
+		return nil, fmt.Errorf("locating %s: %w", path, err)
+	}
+	names := make([]string, len(pkg.GoFiles))
+	for i, name := range pkg.GoFiles {
+		names[i] = filepath.Join(pkg.Dir, name)
+	}
+	files, err := r.parseFiles(names)
+	if err != nil {
+		return nil, err
+	}
+	r.pkgs[path] = r.structDecls(files, path)
+	return r.pkgs[path], nil
+}
+
+// candidate is a column field found while flattening a model, at an embedding depth
+type candidate struct {
+	field
+	depth int
+}
+
+// model collects the column fields of the struct decl, applying the registry's rules:
+// embedded structs without a db tag are flattened with promoted names, structs tagged
+// inline with qualified names, and a shallower field shadows deeper ones
+func (r *resolver) model(name string, decl structDecl, untagged bool) (model, error) {
+	m := model{Name: name, imports: make(map[string]string)}
+	c := &collector{resolver: r, model: &m, untagged: untagged, visiting: make(map[*ast.StructType]bool)}
+	if err := c.collect(decl, "", 0); err != nil {
+		return model{}, fmt.Errorf("%s: %w", name, err)
+	}
+
+	depth := make(map[string]int)
+	for _, cand := range c.fields {
+		if d, ok := depth[cand.Name]; !ok || cand.depth < d {
+			depth[cand.Name] = cand.depth
+		}
+	}
+	seen := make(map[string]bool)
+	handles := make(map[string]string)
+	for _, cand := range c.fields {
+		if cand.depth > depth[cand.Name] {
+			continue
+		}
+		if seen[cand.Name] {
+			return model{}, fmt.Errorf("%s: field %s is ambiguous", name, cand.Name)
+		}
+		seen[cand.Name] = true
+		if !exported(cand.Name) {
+			continue
+		}
+		handle := cand.handleName()
+		if other, ok := handles[handle]; ok {
+			return model{}, fmt.Errorf("%s: fields %s and %s would both get the handle %s", name, other, cand.Name, handle)
+		}
+		handles[handle] = cand.Name
+		m.Fields = append(m.Fields, cand.field)
+	}
+	return m, nil
+}
+
+type collector struct {
+	*resolver
+	model    *model
+	untagged bool
+	fields   []candidate
+	visiting map[*ast.StructType]bool
+}
+
+// collect walks the fields of decl; prefix qualifies the names of inline fields and depth
+// counts embedding levels
+func (c *collector) collect(decl structDecl, prefix string, depth int) error {
+	if c.visiting[decl.st] {
+		return fmt.Errorf("struct embeds itself")
+	}
+	c.visiting[decl.st] = true
+	defer delete(c.visiting, decl.st)
+
+	for _, f := range decl.st.Fields.List {
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			unquoted, _ := strconv.Unquote(f.Tag.Value)
+			tag = reflect.StructTag(unquoted)
+		}
+		if _, ok := tag.Lookup("rel"); ok {
+			continue
+		}
+		dbTag, tagged := tag.Lookup("db")
+		if dbTag == "-" {
+			continue
+		}
+
+		names := f.Names
+		if len(f.Names) == 0 {
+			// An embedded field is named after its type
+			names = []*ast.Ident{embeddedName(f.Type)}
+		}
+		if inline := isInline(dbTag); inline || (len(f.Names) == 0 && !tagged) {
+			target, ok, err := c.resolve(decl, f.Type)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				if inline {
+					return fmt.Errorf("field %s%s: inline requires a struct declared in Go source", prefix, names[0].Name)
+				}
+				continue
+			}
+			if !inline {
+				if err := c.collect(target, prefix, depth+1); err != nil {
+					return err
+				}
+				continue
+			}
+			for _, n := range names {
+				if err := c.collect(target, prefix+n.Name+".", depth); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		typ := ""
+		for _, n := range names {
+			// Untagged fields are columns only when exported, in untagged mode
+			if dbTag == "" && (!c.untagged || !n.IsExported()) {
+				continue
+			}
+			if typ == "" {
+				typ = qualifyLocal(exprString(c.fset, f.Type), decl.pkgName, decl.pkgPath, c.model.imports)
+				collectImports(f.Type, decl.imports, c.model.imports)
+			}
+			c.fields = append(c.fields, candidate{field: field{Name: prefix + n.Name, Type: typ}, depth: depth})
+		}
+	}
+	return nil
+}
+
+// isInline reports whether a db tag marks an inline struct, e.g. db:"addr,inline"
+func isInline(dbTag string) bool {
+	parts := strings.Split(dbTag, ",")
+	for _, opt := range parts[1:] {
+		if strings.TrimSpace(opt) == "inline" {
+			return true
+		}
+	}
+	return false
+}
+
+// embeddedName returns the name of an embedded field of type typ (T, *T or pkg.T)
+func embeddedName(typ ast.Expr) *ast.Ident {
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	switch t := typ.(type) {
+	case *ast.SelectorExpr:
+		return t.Sel
+	case *ast.IndexExpr:
+		return embeddedName(t.X)
+	case *ast.IndexListExpr:
+		return embeddedName(t.X)
+	case *ast.Ident:
+		return t
+	}
+	return ast.NewIdent("_")
+}
+
+// exported reports whether every part of a qualified field name is exported
+func exported(name string) bool {
+	for _, part := range strings.Split(name, ".") {
+		if !token.IsExported(part) {
+			return false
+		}
+	}
+	return true
+}
+
+func typeSpecs(file *ast.File) []*ast.TypeSpec {
+	var specs []*ast.TypeSpec
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.TYPE {
+			for _, spec := range gen.Specs {
+				specs = append(specs, spec.(*ast.TypeSpec))
+			}
+		}
+	}
+	return specs
+}
+
+// fileImports maps the package names used in file to their import paths
+func fileImports(file *ast.File) map[string]string {
+	imports := make(map[string]string)
+	for _, imp := range file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		imports[name] = path
+	}
+	return imports
+}
+
+func exprString(fset *token.FileSet, e ast.Expr) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, e)
+	return buf.String()
+}
+
+// collectImports records the imports referenced by selector expressions (e.g. time.Time)
+func collectImports(e ast.Expr, fileImports, used map[string]string) {
+	ast.Inspect(e, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if x, ok := sel.X.(*ast.Ident); ok {
+			if path, ok := fileImports[x.Name]; ok {
+				used[x.Name] = path
+			}
+		}
+		return false
+	})
+}
+
+// qualifyLocal prefixes identifiers declared in the package pkgName with its name, and
+// records its import path pkgPath ("" for the model package) in imports
+func qualifyLocal(typ, pkgName, pkgPath string, imports map[string]string) string {
+	expr, err := parser.ParseExpr(typ)
+	if err != nil {
+		return typ
+	}
+	qualified := false
+	var rewrite func(e ast.Expr) ast.Expr
+	rewrite = func(e ast.Expr) ast.Expr {
+		switch t := e.(type) {
+		case *ast.Ident:
+			if types.Universe.Lookup(t.Name) != nil {
+				return t
+			}
+			qualified = true
+			return &ast.SelectorExpr{X: ast.NewIdent(pkgName), Sel: t}
+		case *ast.StarExpr:
+			t.X = rewrite(t.X)
+		case *ast.ArrayType:
+			t.Elt = rewrite(t.Elt)
+		case *ast.MapType:
+			t.Key = rewrite(t.Key)
+			t.Value = rewrite(t.Value)
+		case *ast.IndexExpr:
+			t.X = rewrite(t.X)
+			t.Index = rewrite(t.Index)
+		}
+		return e
+	}
+	expr = rewrite(expr)
+	if qualified {
+		imports[pkgName] = pkgPath
+	}
+	return exprString(token.NewFileSet(), expr)
+}
+
+// generate renders the Go source holding the column handles
+func generate(pkg, srcImportPath string, models []model) ([]byte, error) {
+	imports := map[string]string{"querybuilder": querybuilderPath}
+	for _, m := range models {
+		for name, path := range m.imports {
+			if path == "" {
+				path = srcImportPath
+			}
+			imports[name] = path
+		}
+	}
+	paths := make([]string, 0, len(imports))
+	for _, path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by colgen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	fmt.Fprintf(&buf, "import (\n")
+	for _, path := range paths {
+		fmt.Fprintf(&buf, "\t%q\n", path)
+	}
+	fmt.Fprintf(&buf, ")\n")
+
+	for _, m := range models {
+		fmt.Fprintf(&buf, "\n// %sCols holds typed column handles for %s\n", m.Name, m.Name)
+		fmt.Fprintf(&buf, "var %sCols = struct {\n", m.Name)
+		for _, f := range m.Fields {
+			fmt.Fprintf(&buf, "\t%s querybuilder.Column[%s]\n", f.handleName(), f.Type)
+		}
+		fmt.Fprintf(&buf, "}{\n")
+		for _, f := range m.Fields {
+			fmt.Fprintf(&buf, "\t%s: querybuilder.NewColumn[%s](%q),\n", f.handleName(), f.Type, f.Name)
+		}
+		fmt.Fprintf(&buf, "}\n")
+	}
+
+	return format.Source(buf.Bytes())
+}
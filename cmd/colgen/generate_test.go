@@ -0,0 +1,199 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testModelSrc = `package model
+
+import "time"
+
+type Role string
+
+type Account struct {
+	ID        int       ` + "`db:\"id\"`" + `
+	Role      Role      ` + "`db:\"role\"`" + `
+	CreatedAt time.Time ` + "`db:\"created_at\"`" + `
+	Secret    string    ` + "`db:\"-\"`" + `
+	Note      string
+}
+
+type NoTags struct {
+	ID int
+}
+
+type Post struct {
+	ID      int      ` + "`db:\",pk\"`" + `
+	Title   string
+	private string
+	Account *Account ` + "`rel:\"belongs_to\"`" + `
+}
+`
+
+func TestParseModels(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "account.go"), []byte(testModelSrc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	models, err := parseModels(dir, nil, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(models) != 2 || models[0].Name != "Account" || models[1].Name != "Post" {
+		t.Fatalf("Expected Account and Post models, got %+v", models)
+	}
+
+	expected := []field{
+		{Name: "ID", Type: "int"},
+		{Name: "Role", Type: "model.Role"},
+		{Name: "CreatedAt", Type: "time.Time"},
+	}
+	if len(models[0].Fields) != len(expected) {
+		t.Fatalf("Expected %d fields, got %+v", len(expected), models[0].Fields)
+	}
+	for i, f := range expected {
+		if models[0].Fields[i] != f {
+			t.Errorf("Expected field %+v, got %+v", f, models[0].Fields[i])
+		}
+	}
+}
+
+func TestParseModels_Untagged(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "account.go"), []byte(testModelSrc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	models, err := parseModels(dir, nil, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string][]field{
+		"Account": {{"ID", "int"}, {"Role", "model.Role"}, {"CreatedAt", "time.Time"}, {"Note", "string"}},
+		"NoTags":  {{"ID", "int"}},
+		"Post":    {{"ID", "int"}, {"Title", "string"}},
+	}
+	if len(models) != len(expected) {
+		t.Fatalf("Expected %d models, got %+v", len(expected), models)
+	}
+	for _, m := range models {
+		if !reflect.DeepEqual(m.Fields, expected[m.Name]) {
+			t.Errorf("Expected %s fields %+v, got %+v", m.Name, expected[m.Name], m.Fields)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "account.go"), []byte(testModelSrc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	models, err := parseModels(dir, []string{"Account"}, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	src, err := generate("columns", "example.com/app/model", models)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	out := string(src)
+	for _, want := range []string{
+		`"example.com/app/model"`,
+		`"little-orm/internal/database/querybuilder"`,
+		`"time"`,
+		"var AccountCols = struct {",
+		"Role      querybuilder.Column[model.Role]",
+		`CreatedAt: querybuilder.NewColumn[time.Time]("CreatedAt"),`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+const testFlattenSrc = `package mm
+
+import "time"
+
+type Timestamps struct {
+	CreatedAt time.Time ` + "`db:\"created_at\"`" + `
+	UpdatedAt time.Time ` + "`db:\"updated_at\"`" + `
+}
+
+type Address struct {
+	City string ` + "`db:\"city\"`" + `
+	Zip  string ` + "`db:\"zip\"`" + `
+}
+
+type named struct {
+	Name string ` + "`db:\"name\"`" + `
+}
+
+type Customer struct {
+	ID   int    ` + "`db:\"id,pk\"`" + `
+	Name string ` + "`db:\"full_name\"`" + `
+	*named
+	Timestamps
+	Addr    Address  ` + "`db:\"addr,inline\"`" + `
+	Billing *Address ` + "`db:\"billing,inline,prefix=bill_\"`" + `
+	time.Time
+}
+
+type Ambiguous struct {
+	Timestamps
+	Other
+}
+
+type Other struct {
+	CreatedAt int ` + "`db:\"other_created_at\"`" + `
+}
+`
+
+func TestParseModels_Flatten(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "customer.go"), []byte(testFlattenSrc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	models, err := parseModels(dir, []string{"Customer"}, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Customer.Name shadows the promoted named.Name; time.Time has no column
+	expected := []field{
+		{"ID", "int"}, {"Name", "string"}, {"CreatedAt", "time.Time"}, {"UpdatedAt", "time.Time"},
+		{"Addr.City", "string"}, {"Addr.Zip", "string"}, {"Billing.City", "string"}, {"Billing.Zip", "string"},
+	}
+	if len(models) != 1 || !reflect.DeepEqual(models[0].Fields, expected) {
+		t.Fatalf("Expected Customer fields %+v, got %+v", expected, models)
+	}
+
+	src, err := generate("columns", "example.com/app/mm", models)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"CreatedAt   querybuilder.Column[time.Time]",
+		`AddrCity:    querybuilder.NewColumn[string]("Addr.City"),`,
+		`BillingZip:  querybuilder.NewColumn[string]("Billing.Zip"),`,
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", want, src)
+		}
+	}
+
+	if _, err := parseModels(dir, []string{"Ambiguous"}, false); err == nil || !strings.Contains(err.Error(), "field CreatedAt is ambiguous") {
+		t.Errorf("Expected an ambiguous field error, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+// Command colgen generates typed column handles for models with db tags.
+//
+// Usage (from a go:generate directive in the model package):
+//
+//	//go:generate go run little-orm/cmd/colgen -out columns/columns_gen.go
+//
+// For every struct with db-tagged fields it emits a variable such as
+// UserCols whose fields are querybuilder.Column[T] handles carrying the
+// Go type of the model field. Embedded and inline structs are flattened as
+// the registry does, so an inline Address.City gets the handle AddressCity.
+// For models registered with MapUntaggedFields, -untagged makes exported
+// fields without a db tag columns too.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the model package")
+	out := flag.String("out", "columns/columns_gen.go", "output file, relative to -dir")
+	pkg := flag.String("pkg", "", "output package name (defaults to the output directory name)")
+	types := flag.String("types", "", "comma separated list of struct names (defaults to all structs with db tags)")
+	untagged := flag.Bool("untagged", false, "treat exported fields without a db tag as columns, as registry.MapUntaggedFields does")
+	flag.Parse()
+
+	if err := run(*dir, *out, *pkg, *types, *untagged); err != nil {
+		fmt.Fprintln(os.Stderr, "colgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, out, pkg, types string, untagged bool) error {
+	outPath := out
+	if !filepath.IsAbs(outPath) {
+		outPath = filepath.Join(dir, out)
+	}
+	if pkg == "" {
+		absOut, err := filepath.Abs(outPath)
+		if err != nil {
+			return err
+		}
+		pkg = filepath.Base(filepath.Dir(absOut))
+	}
+
+	srcImportPath, err := importPath(dir)
+	if err != nil {
+		return err
+	}
+
+	var only []string
+	if types != "" {
+		only = strings.Split(types, ",")
+	}
+
+	models, err := parseModels(dir, only, untagged)
+	if err != nil {
+		return err
+	}
+	if len(models) == 0 {
+		return fmt.Errorf("no struct with db tags found in %s", dir)
+	}
+
+	src, err := generate(pkg, srcImportPath, models)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(outPath, src, 0o644)
+}
+
+// importPath derives the import path of dir from the enclosing go.mod
+func importPath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for root := abs; ; root = filepath.Dir(root) {
+		data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+		if err == nil {
+			module := modulePath(data)
+			if module == "" {
+				return "", fmt.Errorf("no module directive in %s", filepath.Join(root, "go.mod"))
+			}
+			rel, err := filepath.Rel(root, abs)
+			if err != nil {
+				return "", err
+			}
+			if rel == "." {
+				return module, nil
+			}
+			return module + "/" + filepath.ToSlash(rel), nil
+		}
+		if filepath.Dir(root) == root {
+			return "", fmt.Errorf("go.mod not found above %s", abs)
+		}
+	}
+}
+
+func modulePath(gomod []byte) string {
+	for _, line := range strings.Split(string(gomod), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "module ") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`)
+		}
+	}
+	return ""
+}
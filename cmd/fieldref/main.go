@@ -0,0 +1,19 @@
+// Command fieldref checks querybuilder field references against model structs.
+//
+// Run it through go vet:
+//
+//	go build -o /tmp/fieldref ./cmd/fieldref
+//	go vet -vettool=/tmp/fieldref ./...
+//
+// Add -fieldref.untagged when models are registered with MapUntaggedFields.
+package main
+
+import (
+	"little-orm/internal/analysis/fieldref"
+
+	"golang.org/x/tools/go/analysis/unitchecker"
+)
+
+func main() {
+	unitchecker.Main(fieldref.Analyzer)
+}
@@ -0,0 +1,381 @@
+// Package fieldref defines an analyzer that checks the string field names
+// passed to the query builder against the db-tagged fields of the model.
+//
+// It catches at vet time what ExprValidator only reports at runtime:
+//
+//	querybuilder.NewSelectBuilder(model.User{}).Where(querybuilder.C("Emial"))
+//	// field "Emial" is not a db-tagged field of model.User
+//
+// For models registered with registry.MapUntaggedFields, the -untagged flag makes
+// exported fields without a db tag columns too.
+package fieldref
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"little-orm/internal/database/registry"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+const querybuilderPath = "little-orm/internal/database/querybuilder"
+
+var Analyzer = &analysis.Analyzer{
+	Name: "fieldref",
+	Doc:  "check string field references passed to querybuilder against the model's db-tagged fields",
+	Run:  run,
+}
+
+// mapUntagged mirrors registry.MapUntaggedFields, set by the -untagged flag
+var mapUntagged bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&mapUntagged, "untagged", false, "treat exported fields without a db tag as columns, as registry.MapUntaggedFields does")
+}
+
+// fieldHelpers are querybuilder functions whose first argument is a Go field name
+var fieldHelpers = map[string]bool{
+	"C":          true,
+	"Col":        true,
+	"Eq":         true,
+	"Neq":        true,
+	"Gt":         true,
+	"Lt":         true,
+	"Gte":        true,
+	"Lte":        true,
+	"Like":       true,
+	"In":         true,
+	"NotIn":      true,
+	"Between":    true,
+	"NotBetween": true,
+	"IsNull":     true,
+	"IsNotNull":  true,
+}
+
+// builderMethods are SelectBuilder methods whose arguments are checked
+var builderMethods = map[string]bool{
+	"Select":      true,
+	"SelectExpr":  true,
+	"Where":       true,
+	"AndWhere":    true,
+	"OrderBy":     true,
+	"OrderByExpr": true,
+}
+
+type checker struct {
+	pass *analysis.Pass
+	// builders maps variables holding a *SelectBuilder to their model
+	builders map[types.Object]*types.Named
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	c := &checker{pass: pass, builders: make(map[types.Object]*types.Named)}
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				c.trackAssign(n.Lhs, n.Rhs)
+			case *ast.ValueSpec:
+				lhs := make([]ast.Expr, len(n.Names))
+				for i, name := range n.Names {
+					lhs[i] = name
+				}
+				c.trackAssign(lhs, n.Values)
+			case *ast.CallExpr:
+				c.checkCall(n)
+			}
+			return true
+		})
+	}
+	return nil, nil
+}
+
+// trackAssign remembers which model a builder variable was created for
+func (c *checker) trackAssign(lhs, rhs []ast.Expr) {
+	if len(lhs) != len(rhs) {
+		return
+	}
+	for i, r := range rhs {
+		id, ok := lhs[i].(*ast.Ident)
+		if !ok {
+			continue
+		}
+		m := c.builderModel(r)
+		if m == nil {
+			continue
+		}
+		if obj := c.pass.TypesInfo.ObjectOf(id); obj != nil {
+			c.builders[obj] = m
+		}
+	}
+}
+
+// builderModel resolves the model of a *SelectBuilder-valued expression
+func (c *checker) builderModel(e ast.Expr) *types.Named {
+	switch e := ast.Unparen(e).(type) {
+	case *ast.Ident:
+		return c.builders[c.pass.TypesInfo.ObjectOf(e)]
+	case *ast.CallExpr:
+		if fn := c.calledFunc(e); fn != nil {
+			switch {
+			case fn.Name() == "NewSelectBuilder" && len(e.Args) == 1:
+				return modelType(c.pass.TypesInfo.TypeOf(e.Args[0]))
+			case fn.Name() == "NewSelectBuilderWith" && len(e.Args) == 2:
+				return modelType(c.pass.TypesInfo.TypeOf(e.Args[1]))
+			}
+		}
+		if sel, ok := ast.Unparen(e.Fun).(*ast.SelectorExpr); ok && c.isBuilderMethod(sel) {
+			return c.builderModel(sel.X)
+		}
+	}
+	return nil
+}
+
+func (c *checker) checkCall(call *ast.CallExpr) {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok || !c.isBuilderMethod(sel) || !builderMethods[sel.Sel.Name] {
+		return
+	}
+	m := c.builderModel(sel.X)
+	if m == nil {
+		return
+	}
+	fields := dbFields(m)
+
+	switch sel.Sel.Name {
+	case "Select":
+		for _, arg := range call.Args {
+			c.checkName(arg, m, fields, false)
+		}
+	case "OrderBy":
+		if len(call.Args) > 0 {
+			// OrderBy renders its argument verbatim, so db column names are fine too
+			c.checkName(call.Args[0], m, fields, true)
+		}
+	default:
+		for _, arg := range call.Args {
+			c.checkExpr(arg, m, fields)
+		}
+	}
+}
+
+// checkExpr checks every field helper call nested in an expression argument
+func (c *checker) checkExpr(e ast.Expr, m *types.Named, fields map[string]string) {
+	ast.Inspect(e, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		fn := c.calledFunc(call)
+		if fn == nil || len(call.Args) == 0 {
+			return true
+		}
+		switch {
+		case fieldHelpers[fn.Name()]:
+			c.checkName(call.Args[0], m, fields, false)
+		case fn.Name() == "Raw":
+			c.checkRaw(call.Args[0], m, fields)
+		}
+		return true
+	})
+}
+
+func (c *checker) checkName(arg ast.Expr, m *types.Named, fields map[string]string, allowDBTag bool) {
+	tv, ok := c.pass.TypesInfo.Types[arg]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return
+	}
+	name := constant.StringVal(tv.Value)
+	if _, ok := fields[name]; ok {
+		return
+	}
+	if allowDBTag {
+		for _, tag := range fields {
+			if tag == name {
+				return
+			}
+		}
+	}
+	c.reportField(arg, name, m)
+}
+
+func (c *checker) reportField(arg ast.Expr, name string, m *types.Named) {
+	kind := "db-tagged"
+	if mapUntagged {
+		kind = "mapped"
+	}
+	c.pass.Reportf(arg.Pos(), "field %q is not a %s field of %s", name, kind, typeName(m))
+}
+
+// columnRefPattern matches the {{col:Field}} references of querybuilder.Raw fragments
+var columnRefPattern = regexp.MustCompile(`\{\{\s*col:([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// checkRaw checks the column references of a constant raw SQL fragment
+func (c *checker) checkRaw(arg ast.Expr, m *types.Named, fields map[string]string) {
+	tv, ok := c.pass.TypesInfo.Types[arg]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return
+	}
+	for _, ref := range columnRefPattern.FindAllStringSubmatch(constant.StringVal(tv.Value), -1) {
+		if _, ok := fields[ref[1]]; !ok {
+			c.reportField(arg, ref[1], m)
+		}
+	}
+}
+
+// calledFunc returns the querybuilder package-level function called by call, if any
+func (c *checker) calledFunc(call *ast.CallExpr) *types.Func {
+	fun := ast.Unparen(call.Fun)
+	if idx, ok := fun.(*ast.IndexExpr); ok {
+		fun = idx.X
+	}
+	var id *ast.Ident
+	switch f := fun.(type) {
+	case *ast.Ident:
+		id = f
+	case *ast.SelectorExpr:
+		id = f.Sel
+	default:
+		return nil
+	}
+	fn, ok := c.pass.TypesInfo.Uses[id].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != querybuilderPath {
+		return nil
+	}
+	if sig, ok := fn.Type().(*types.Signature); ok && sig.Recv() != nil {
+		return nil
+	}
+	return fn
+}
+
+// isBuilderMethod reports whether sel is a method of querybuilder.SelectBuilder
+func (c *checker) isBuilderMethod(sel *ast.SelectorExpr) bool {
+	selection, ok := c.pass.TypesInfo.Selections[sel]
+	if !ok || selection.Kind() != types.MethodVal {
+		return false
+	}
+	named := namedOf(selection.Recv())
+	return named != nil && named.Obj().Pkg() != nil &&
+		named.Obj().Pkg().Path() == querybuilderPath && named.Obj().Name() == "SelectBuilder"
+}
+
+func namedOf(t types.Type) *types.Named {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	n, _ := t.(*types.Named)
+	return n
+}
+
+// modelType returns the named struct type of a model value (or pointer to it)
+func modelType(t types.Type) *types.Named {
+	n := namedOf(t)
+	if n == nil {
+		return nil
+	}
+	if _, ok := n.Underlying().(*types.Struct); !ok {
+		return nil
+	}
+	return n
+}
+
+// dbFields maps the db-tagged Go field names of a model to their column names.
+// Embedded structs are flattened and inline struct fields qualified, as in the registry;
+// fields without a column name are named like the default naming strategy does.
+func dbFields(m *types.Named) map[string]string {
+	var candidates []fieldCandidate
+	collectFields(m.Underlying().(*types.Struct), "", "", 0, &candidates, map[*types.Struct]bool{})
+
+	// As in the registry, a shallower field shadows deeper ones with the same name,
+	// and fields at the same depth are ambiguous, so neither is usable
+	depth := make(map[string]int)
+	count := make(map[string]int)
+	for _, cand := range candidates {
+		if d, ok := depth[cand.name]; !ok || cand.depth < d {
+			depth[cand.name] = cand.depth
+			count[cand.name] = 0
+		}
+		if cand.depth == depth[cand.name] {
+			count[cand.name]++
+		}
+	}
+	fields := make(map[string]string)
+	for _, cand := range candidates {
+		if cand.depth == depth[cand.name] && count[cand.name] == 1 {
+			fields[cand.name] = cand.column
+		}
+	}
+	return fields
+}
+
+// fieldCandidate is a column field found at an embedding depth
+type fieldCandidate struct {
+	name, column string
+	depth        int
+}
+
+func collectFields(st *types.Struct, name, colPrefix string, depth int, candidates *[]fieldCandidate, visiting map[*types.Struct]bool) {
+	if visiting[st] {
+		return
+	}
+	visiting[st] = true
+	defer delete(visiting, st)
+
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		structTag := reflect.StructTag(st.Tag(i))
+		if _, ok := structTag.Lookup("rel"); ok {
+			continue
+		}
+		tag, tagged := structTag.Lookup("db")
+		if tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		inline, prefix := false, ""
+		for _, opt := range parts[1:] {
+			if opt == "inline" {
+				inline = true
+			} else if p, ok := strings.CutPrefix(opt, "prefix="); ok {
+				prefix = p
+			}
+		}
+
+		if inline || (f.Anonymous() && !tagged) {
+			typ := f.Type()
+			if p, ok := typ.(*types.Pointer); ok {
+				typ = p.Elem()
+			}
+			inner, ok := typ.Underlying().(*types.Struct)
+			if !ok {
+				continue
+			}
+			if !inline {
+				collectFields(inner, name, colPrefix, depth+1, candidates, visiting)
+				continue
+			}
+			if prefix == "" && parts[0] != "" {
+				prefix = parts[0] + "_"
+			}
+			collectFields(inner, name+f.Name()+".", colPrefix+prefix, depth, candidates, visiting)
+			continue
+		}
+		if tag == "" && (!mapUntagged || !f.Exported()) {
+			continue
+		}
+		column := parts[0]
+		if column == "" {
+			column = registry.ToSnakeCase(f.Name())
+		}
+		*candidates = append(*candidates, fieldCandidate{name: name + f.Name(), column: colPrefix + column, depth: depth})
+	}
+}
+
+func typeName(m *types.Named) string {
+	return m.Obj().Pkg().Name() + "." + m.Obj().Name()
+}
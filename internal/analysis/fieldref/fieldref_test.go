@@ -0,0 +1,18 @@
+package fieldref
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "little-orm/a")
+}
+
+func TestAnalyzer_Untagged(t *testing.T) {
+	Analyzer.Flags.Set("untagged", "true")
+	defer Analyzer.Flags.Set("untagged", "false")
+
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "little-orm/untagged")
+}
@@ -0,0 +1,79 @@
+package a
+
+import qb "little-orm/internal/database/querybuilder"
+
+type Timestamps struct {
+	CreatedAt string `db:"created_at"`
+}
+
+type Address struct {
+	City string `db:"city"`
+}
+
+type User struct {
+	Timestamps
+	Home     Address `db:"home,inline"`
+	ID       int     `db:"id"`
+	Email    string  `db:"email"`
+	Password string  `db:"-"`
+	Note     string
+}
+
+const emailField = "Email"
+
+func chained() {
+	qb.NewSelectBuilder(User{}).
+		Select("ID", emailField, "Mail"). // want `field "Mail" is not a db-tagged field of a.User`
+		Where(qb.And(
+			qb.Eq("ID", 1),
+			qb.Eq("Note", "x"), // want `field "Note" is not a db-tagged field of a.User`
+		)).
+		OrderBy("email", "ASC").
+		OrderBy("Nmae", "DESC") // want `field "Nmae" is not a db-tagged field of a.User`
+}
+
+func variable(field string) {
+	b := qb.NewSelectBuilder(&User{})
+	b.Limit(10).Where(qb.C("Password")) // want `field "Password" is not a db-tagged field of a.User`
+	b.Select(field)
+	b.Select("CreatedAt", "Home.City")
+	b.Select("City") // want `field "City" is not a db-tagged field of a.User`
+	b.OrderBy("home_city", "ASC")
+	b.Where(qb.Raw("{{col:Email}} = lower(?)", "x"))
+	b.Where(qb.Raw("date_trunc('day', {{col:UpdatedAt}}) = ?", 1)) // want `field "UpdatedAt" is not a db-tagged field of a.User`
+}
+
+func withRegistry(reg any) {
+	qb.NewSelectBuilderWith(reg, User{}).Select("ID", "Mail") // want `field "Mail" is not a db-tagged field of a.User`
+}
+
+func unresolved() qb.Expr {
+	return qb.C("Anything")
+}
+
+type Base struct {
+	ID   int    `db:"base_id"`
+	Name string `db:"name"`
+}
+
+type Left struct {
+	Code string `db:"left_code"`
+}
+
+type Right struct {
+	Code string `db:"right_code"`
+}
+
+type Shadowing struct {
+	Base
+	Left
+	*Right
+	ID int `db:"id"`
+}
+
+func shadowing() {
+	qb.NewSelectBuilder(Shadowing{}).
+		Select("ID", "Name", "Code"). // want `field "Code" is not a db-tagged field of a.Shadowing`
+		OrderBy("id", "ASC").
+		OrderBy("base_id", "ASC") // want `field "base_id" is not a db-tagged field of a.Shadowing`
+}
@@ -0,0 +1,21 @@
+// Package querybuilder is a minimal stand-in for the real package.
+package querybuilder
+
+type Expr interface{}
+
+type SortOrder string
+
+type SelectBuilder struct{}
+
+func NewSelectBuilder(model any) *SelectBuilder                       { return &SelectBuilder{} }
+func NewSelectBuilderWith(reg any, model any) *SelectBuilder          { return &SelectBuilder{} }
+func (b *SelectBuilder) Select(fields ...string) *SelectBuilder       { return b }
+func (b *SelectBuilder) Where(e Expr) *SelectBuilder                  { return b }
+func (b *SelectBuilder) OrderBy(f string, o SortOrder) *SelectBuilder { return b }
+func (b *SelectBuilder) Limit(n int) *SelectBuilder                   { return b }
+
+func C(name string) Expr               { return nil }
+func L(val any) Expr                   { return nil }
+func And(exprs ...Expr) Expr           { return nil }
+func Eq(field string, v any) Expr      { return nil }
+func Raw(sql string, args ...any) Expr { return nil }
@@ -0,0 +1,25 @@
+package untagged
+
+import qb "little-orm/internal/database/querybuilder"
+
+type Author struct {
+	ID int `db:"id,pk"`
+}
+
+type Post struct {
+	ID        int `db:",pk"`
+	AuthorID  int
+	CreatedAt string
+	Secret    string `db:"-"`
+	internal  string
+	Author    *Author `rel:"belongs_to"`
+}
+
+func untagged() {
+	qb.NewSelectBuilder(Post{}).
+		Select("ID", "AuthorID", "CreatedAt").
+		Where(qb.Eq("Secret", "x")). // want `field "Secret" is not a mapped field of untagged.Post`
+		OrderBy("created_at", "DESC").
+		OrderBy("Author", "ASC") // want `field "Author" is not a mapped field of untagged.Post`
+	qb.NewSelectBuilder(Post{}).Select("internal") // want `field "internal" is not a mapped field of untagged.Post`
+}
@@ -18,9 +18,10 @@ func init() {
 }
 
 func dbListRegistry() {
-	registry := registry.GetDBRegistry()
-	registry.Register(model.User{})
-	registry.Register(model.Message{})
+	registry.GetDBRegistry().MustRegister(
+		model.User{},
+		model.Message{},
+	)
 }
 
 func GetDB() *sql.DB {
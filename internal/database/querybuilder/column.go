@@ -0,0 +1,35 @@
+package querybuilder
+
+// Column is a typed handle to a model field, T being the Go type of the field.
+// Comparisons against values of another type fail to compile.
+type Column[T any] struct {
+	field string
+}
+
+// NewColumn creates a typed handle for the given Go field name (e.g. "ID")
+func NewColumn[T any](field string) Column[T] {
+	return Column[T]{field: field}
+}
+
+// Name returns the Go field name the handle refers to
+func (c Column[T]) Name() string { return c.field }
+
+// Expr returns the column expression, resolved later by ExprValidator
+func (c Column[T]) Expr() Expr { return C(c.field) }
+
+// Typed comparison helpers
+func (c Column[T]) Eq(v T) Expr              { return B(OpEq, c.Expr(), L(v)) }
+func (c Column[T]) Neq(v T) Expr             { return B(OpNEq, c.Expr(), L(v)) }
+func (c Column[T]) Gt(v T) Expr              { return B(OpGt, c.Expr(), L(v)) }
+func (c Column[T]) Lt(v T) Expr              { return B(OpLt, c.Expr(), L(v)) }
+func (c Column[T]) Gte(v T) Expr             { return B(OpGte, c.Expr(), L(v)) }
+func (c Column[T]) Lte(v T) Expr             { return B(OpLte, c.Expr(), L(v)) }
+func (c Column[T]) Like(pattern string) Expr { return B(OpLike, c.Expr(), L(pattern)) }
+func (c Column[T]) In(values ...T) Expr      { return B(OpIn, c.Expr(), L(values)) }
+func (c Column[T]) NotIn(values ...T) Expr   { return B(OpNIn, c.Expr(), L(values)) }
+func (c Column[T]) Between(low, high T) Expr {
+	return &TernaryExpr{Expr: c.Expr(), Low: L(low), High: L(high)}
+}
+func (c Column[T]) NotBetween(low, high T) Expr { return Not(c.Between(low, high)) }
+func (c Column[T]) IsNull() Expr                { return U(OpIsNull, c.Expr()) }
+func (c Column[T]) IsNotNull() Expr             { return U(OpIsNNull, c.Expr()) }
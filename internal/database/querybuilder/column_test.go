@@ -0,0 +1,77 @@
+package querybuilder
+
+import (
+	"little-orm/internal/model"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testUserCols = struct {
+	ID    Column[int]
+	Email Column[string]
+	Name  Column[string]
+}{
+	ID:    NewColumn[int]("ID"),
+	Email: NewColumn[string]("Email"),
+	Name:  NewColumn[string]("Name"),
+}
+
+func TestColumn_Name(t *testing.T) {
+	if testUserCols.ID.Name() != "ID" {
+		t.Errorf("Expected Name 'ID', got '%s'", testUserCols.ID.Name())
+	}
+}
+
+func TestSelectBuilder_Where_TypedColumns(t *testing.T) {
+	setupTestRegistry()
+
+	builder := NewSelectBuilder(model.User{})
+	query, args := builder.
+		Select(testUserCols.ID.Name(), testUserCols.Name.Name()).
+		Where(And(
+			testUserCols.ID.Gt(10),
+			testUserCols.Name.Like("J%"),
+			testUserCols.ID.In(1, 2, 3),
+			testUserCols.Email.IsNotNull(),
+		)).
+		Build()
+
+	expected := "SELECT id, name FROM users WHERE id > ? AND name LIKE ? AND id IN (?, ?, ?) AND email IS NOT NULL"
+	if query != expected {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expected, query)
+	}
+
+	expectedArgs := []any{10, "J%", 1, 2, 3}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args %v, got %v", expectedArgs, args)
+	}
+}
+
+func TestColumn_Between(t *testing.T) {
+	setupTestRegistry()
+
+	builder := NewSelectBuilder(model.User{})
+	query, args := builder.Where(testUserCols.ID.Between(1, 10)).Build()
+
+	if !strings.Contains(query, "WHERE id BETWEEN ? AND ?") {
+		t.Errorf("Expected BETWEEN clause, got: %s", query)
+	}
+
+	if len(args) != 2 || args[0] != 1 || args[1] != 10 {
+		t.Errorf("Expected args [1, 10], got %v", args)
+	}
+}
+
+func TestColumn_Int64WithUntypedConstants(t *testing.T) {
+	setupTestRegistry()
+
+	// The column's type drives inference, so untyped constants need no conversion
+	id := NewColumn[int64]("ID")
+	_, args := NewSelectBuilder(model.User{}).Where(And(id.Eq(5), id.Between(1, 10), id.In(7, 8))).Build()
+
+	expected := []any{int64(5), int64(1), int64(10), int64(7), int64(8)}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected int64 args, got %#v", args)
+	}
+}
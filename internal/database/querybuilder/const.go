@@ -33,8 +33,26 @@ const (
 	OpIsNNull Op = "IS NOT NULL"
 	OpBetween Op = "BETWEEN"
 
+	// Full-text search operators
+	OpMatch Op = "@@"
+
 	// Logical operators
 	OpAnd Op = "AND"
 	OpOr  Op = "OR"
 	OpNot Op = "NOT"
 )
+
+type TextSearchFunc string
+
+const (
+	TextSearchMatch    TextSearchFunc = "match"
+	TextSearchRank     TextSearchFunc = "ts_rank"
+	TextSearchHeadline TextSearchFunc = "ts_headline"
+)
+
+type TsQueryMode string
+
+const (
+	PlainQuery     TsQueryMode = "plainto_tsquery"
+	WebSearchQuery TsQueryMode = "websearch_to_tsquery"
+)
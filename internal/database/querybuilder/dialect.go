@@ -0,0 +1,198 @@
+package querybuilder
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dialect describes how a SQL flavour renders bind placeholders
+type Dialect interface {
+	// Name returns the dialect name (e.g. "postgres")
+	Name() string
+	// Placeholder returns the placeholder for the n-th (1-based) argument
+	Placeholder(n int) string
+	// QuoteLiteral renders v as a SQL literal, for debug output only
+	QuoteLiteral(v any) string
+}
+
+type questionDialect struct{}
+
+func (questionDialect) Name() string           { return "default" }
+func (questionDialect) Placeholder(int) string { return "?" }
+
+// QuoteLiteral follows MySQL rules: backslashes escape, booleans are 1/0
+func (questionDialect) QuoteLiteral(v any) string {
+	switch v := v.(type) {
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case []byte:
+		return fmt.Sprintf("X'%x'", v)
+	case string:
+		return "'" + strings.NewReplacer(`\`, `\\`, "'", "''").Replace(v) + "'"
+	}
+	return quoteLiteral(v, questionDialect{})
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string             { return "postgres" }
+func (postgresDialect) Placeholder(n int) string { return "$" + strconv.Itoa(n) }
+
+// QuoteLiteral follows PostgreSQL rules with standard_conforming_strings on
+func (postgresDialect) QuoteLiteral(v any) string {
+	switch v := v.(type) {
+	case bool:
+		return strings.ToUpper(strconv.FormatBool(v))
+	case []byte:
+		return fmt.Sprintf("'\\x%x'::bytea", v)
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	}
+	return quoteLiteral(v, postgresDialect{})
+}
+
+// quoteLiteral renders the values common to all dialects, quoting strings with d
+func quoteLiteral(v any, d Dialect) string {
+	if v == nil {
+		return "NULL"
+	}
+	switch v := v.(type) {
+	case NamedParam:
+		return ":" + v.Name
+	case time.Time:
+		return d.QuoteLiteral(v.Format("2006-01-02 15:04:05.999999Z07:00"))
+	case driver.Valuer:
+		value, err := v.Value()
+		if err != nil {
+			return "/* " + err.Error() + " */"
+		}
+		return d.QuoteLiteral(value)
+	}
+
+	// Kinds render from their underlying value, like the driver binds them, so a
+	// named type implementing fmt.Stringer (e.g. an enum) still renders as a number
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, rv.Type().Bits())
+	case reflect.String:
+		return d.QuoteLiteral(rv.String())
+	case reflect.Bool:
+		return d.QuoteLiteral(rv.Bool())
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return "NULL"
+		}
+		return d.QuoteLiteral(rv.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		// IN lists are bound as a single slice argument
+		items := make([]string, rv.Len())
+		for i := range items {
+			items[i] = d.QuoteLiteral(rv.Index(i).Interface())
+		}
+		return "(" + strings.Join(items, ", ") + ")"
+	}
+	if s, ok := v.(fmt.Stringer); ok {
+		return d.QuoteLiteral(s.String())
+	}
+	return d.QuoteLiteral(fmt.Sprint(v))
+}
+
+var (
+	// DefaultDialect keeps the "?" placeholders produced by expressions
+	DefaultDialect Dialect = questionDialect{}
+	// Postgres renders placeholders as $1, $2, ...
+	Postgres Dialect = postgresDialect{}
+)
+
+// Rebind rewrites the "?" placeholders of query into the dialect's style.
+// Question marks inside quoted strings and identifiers are left untouched.
+func Rebind(d Dialect, query string) string {
+	if d == nil || d == DefaultDialect {
+		return query
+	}
+
+	var sb strings.Builder
+	n := 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+			sb.WriteByte(ch)
+		case ch == '\'' || ch == '"':
+			quote = ch
+			sb.WriteByte(ch)
+		case ch == '?':
+			n++
+			sb.WriteString(d.Placeholder(n))
+		default:
+			sb.WriteByte(ch)
+		}
+	}
+	return sb.String()
+}
+
+// debugSQLHeader marks interpolated queries so they are not mistaken for executable SQL
+const debugSQLHeader = "-- DEBUG ONLY: arguments interpolated for inspection, do not execute\n"
+
+// DebugSQL interpolates args into the "?" or "$n" placeholders of query using the literal
+// quoting of d. The result is meant for logs and debugging only, never for execution.
+func DebugSQL(d Dialect, query string, args []any) string {
+	if d == nil {
+		d = DefaultDialect
+	}
+
+	var sb strings.Builder
+	sb.WriteString(debugSQLHeader)
+	n := 0
+	arg := func(i int) string {
+		if i < 0 || i >= len(args) {
+			return "/* missing arg */"
+		}
+		return d.QuoteLiteral(args[i])
+	}
+
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+			sb.WriteByte(ch)
+		case ch == '\'' || ch == '"':
+			quote = ch
+			sb.WriteByte(ch)
+		case ch == '?':
+			sb.WriteString(arg(n))
+			n++
+		case ch == '$' && i+1 < len(query) && query[i+1] >= '0' && query[i+1] <= '9':
+			j := i + 1
+			for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+				j++
+			}
+			idx, _ := strconv.Atoi(query[i+1 : j])
+			sb.WriteString(arg(idx - 1))
+			i = j - 1
+		default:
+			sb.WriteByte(ch)
+		}
+	}
+	return sb.String()
+}
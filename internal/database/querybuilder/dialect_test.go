@@ -0,0 +1,77 @@
+package querybuilder
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Stringers whose text is not the value bound to the query
+type (
+	testStatus  int
+	testRatio   float32
+	testCode    string
+	testVersion struct{ major, minor int }
+)
+
+func (testStatus) String() string  { return "active" }
+func (testRatio) String() string   { return "half" }
+func (testCode) String() string    { return "code" }
+func (testVersion) String() string { return "v1.2" }
+
+func (testCode) Value() (driver.Value, error) { return "db-code", nil }
+
+func TestQuoteLiteral(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	name := "bob"
+	tests := []struct {
+		dialect  Dialect
+		value    any
+		expected string
+	}{
+		{DefaultDialect, "O'Brien", `'O''Brien'`},
+		{DefaultDialect, `a\b`, `'a\\b'`},
+		{Postgres, `a\b`, `'a\b'`},
+		{DefaultDialect, true, "1"},
+		{Postgres, false, "FALSE"},
+		{Postgres, nil, "NULL"},
+		{Postgres, 42, "42"},
+		{Postgres, 1.5, "1.5"},
+		{Postgres, []int{1, 2}, "(1, 2)"},
+		{Postgres, []byte{0xde, 0xad}, `'\xdead'::bytea`},
+		{DefaultDialect, []byte{0xde, 0xad}, "X'dead'"},
+		{Postgres, ts, "'2024-01-02 03:04:05Z'"},
+		{Postgres, &name, "'bob'"},
+		{Postgres, (*string)(nil), "NULL"},
+		{Postgres, testStatus(3), "3"},
+		{Postgres, testRatio(0.5), "0.5"},
+		{Postgres, testCode("x"), "'db-code'"},
+		{Postgres, testVersion{1, 2}, "'v1.2'"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.dialect.QuoteLiteral(tt.value); got != tt.expected {
+			t.Errorf("%s: expected %s for %#v, got %s", tt.dialect.Name(), tt.expected, tt.value, got)
+		}
+	}
+}
+
+func TestDebugSQL(t *testing.T) {
+	got := DebugSQL(Postgres, "SELECT id FROM users WHERE name = $2 AND id > $1 AND note = '$1?'", []any{10, "it's"})
+	if !strings.HasPrefix(got, debugSQLHeader) {
+		t.Errorf("Expected debug header, got %s", got)
+	}
+	expected := "SELECT id FROM users WHERE name = 'it''s' AND id > 10 AND note = '$1?'"
+	if body := strings.TrimPrefix(got, debugSQLHeader); body != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, body)
+	}
+}
+
+func TestDebugSQL_QuestionPlaceholders(t *testing.T) {
+	got := strings.TrimPrefix(DebugSQL(DefaultDialect, "SELECT * FROM users WHERE (id IN ?) AND name = ? AND x = ?", []any{[]int{1, 2}, "a"}), debugSQLHeader)
+	expected := "SELECT * FROM users WHERE (id IN (1, 2)) AND name = 'a' AND x = /* missing arg */"
+	if got != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, got)
+	}
+}
@@ -2,9 +2,12 @@ package querybuilder
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 )
 
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
 type Expr interface {
 	ToSQL() (string, []any)
 }
@@ -31,12 +34,13 @@ type UnaryExpr struct {
 }
 
 func (u *UnaryExpr) ToSQL() (string, []any) {
-	operandSQL, args := u.Operand.ToSQL()
 	switch u.Operator {
 	case "IS NULL", "IS NOT NULL":
-		return fmt.Sprintf("%s %s", operandSQL, u.Operator), args
+		sql, args := operandSQL(precCompare, u.Operand)
+		return fmt.Sprintf("%s %s", sql, u.Operator), args
 	case "NOT":
-		return fmt.Sprintf("NOT (%s)", operandSQL), args
+		sql, args := u.Operand.ToSQL()
+		return fmt.Sprintf("NOT (%s)", sql), args
 	default:
 		panic("unsupported unary operator: " + u.Operator)
 	}
@@ -49,9 +53,9 @@ type TernaryExpr struct {
 }
 
 func (b *TernaryExpr) ToSQL() (string, []any) {
-	colSQL, colArgs := b.Expr.ToSQL()
-	lowSQL, lowArgs := b.Low.ToSQL()
-	highSQL, highArgs := b.High.ToSQL()
+	colSQL, colArgs := operandSQL(precCompare, b.Expr)
+	lowSQL, lowArgs := operandSQL(precCompare, b.Low)
+	highSQL, highArgs := operandSQL(precCompare, b.High)
 
 	sql := fmt.Sprintf("%s BETWEEN %s AND %s", colSQL, lowSQL, highSQL)
 	args := append(colArgs, lowArgs...)
@@ -73,22 +77,132 @@ func (b *BinaryExpr) ToSQL() (string, []any) {
 		panic("Binary Expression don't have enough Left or Right")
 	}
 
-	leftSQL, leftArgs := b.Left.ToSQL()
-	rightSQL, rightArgs := b.Right.ToSQL()
-
-	var sql strings.Builder
-	var args []any
-
 	switch b.Operator {
-	case OpAnd, OpOr, OpIn, OpNIn:
-		sql.WriteString(fmt.Sprintf("(%s %s %s)", leftSQL, b.Operator, rightSQL))
-		args = append(leftArgs, rightArgs...)
-	case OpEq, OpNEq, OpGt, OpLt, OpGte, OpLte, OpLike:
-		sql.WriteString(fmt.Sprintf("%s %s %s", leftSQL, b.Operator, rightSQL))
-		args = append(leftArgs, rightArgs...)
+	case OpIn, OpNIn:
+		// A literal list gets one placeholder per element so the query binds
+		// without help from the executor. An empty list matches no row with IN
+		// and every row with NOT IN, which no list of values can express.
+		if lit, ok := b.Right.(*LiteralExpr); ok {
+			if list, ok := sliceArg(lit.Value); ok {
+				if len(list) == 0 {
+					if b.Operator == OpIn {
+						return "1=0", nil
+					}
+					return "1=1", nil
+				}
+				leftSQL, leftArgs := operandSQL(precedence(b), b.Left)
+				placeholders := "(?" + strings.Repeat(", ?", len(list)-1) + ")"
+				return fmt.Sprintf("%s %s %s", leftSQL, b.Operator, placeholders), append(leftArgs, list...)
+			}
+		}
+	case OpAnd, OpOr, OpEq, OpNEq, OpGt, OpLt, OpGte, OpLte, OpLike:
 	default:
 		return "", nil
 	}
 
-	return sql.String(), args
+	prec := precedence(b)
+	leftSQL, leftArgs := operandSQL(prec, b.Left)
+	rightSQL, rightArgs := operandSQL(prec, b.Right)
+	return fmt.Sprintf("%s %s %s", leftSQL, b.Operator, rightSQL), append(leftArgs, rightArgs...)
+}
+
+type AliasExpr struct {
+	Expr  Expr
+	Alias string
+}
+
+func (a *AliasExpr) ToSQL() (string, []any) {
+	if a.Expr == nil {
+		panic("Alias Expression don't have an Expr")
+	}
+	if !identifierPattern.MatchString(a.Alias) {
+		panic("invalid alias: " + a.Alias)
+	}
+	sql, args := a.Expr.ToSQL()
+	return fmt.Sprintf("%s AS %s", sql, a.Alias), args
+}
+
+// LogicalExpr is an n-ary AND/OR, produced by Simplify when flattening nested chains
+type LogicalExpr struct {
+	Operator Op
+	Operands []Expr
+}
+
+func (l *LogicalExpr) ToSQL() (string, []any) {
+	if len(l.Operands) == 0 {
+		panic("Logical Expression don't have any Operands")
+	}
+	if l.Operator != OpAnd && l.Operator != OpOr {
+		panic("unsupported logical operator: " + l.Operator)
+	}
+	if len(l.Operands) == 1 {
+		return l.Operands[0].ToSQL()
+	}
+
+	prec := precedence(l)
+	parts := make([]string, len(l.Operands))
+	var args []any
+	for i, operand := range l.Operands {
+		sql, operandArgs := operandSQL(prec, operand)
+		parts[i] = sql
+		args = append(args, operandArgs...)
+	}
+	return strings.Join(parts, " "+string(l.Operator)+" "), args
+}
+
+// Binding strength of SQL operators, from loosest to tightest
+const (
+	precRaw = iota
+	precOr
+	precAnd
+	precNot
+	precCompare
+	precOperand
+)
+
+// precedence returns how tightly e binds; raw SQL is opaque, so it binds loosest
+func precedence(e Expr) int {
+	switch e := e.(type) {
+	case *BinaryExpr:
+		switch e.Operator {
+		case OpOr:
+			return precOr
+		case OpAnd:
+			return precAnd
+		}
+		return precCompare
+	case *LogicalExpr:
+		if len(e.Operands) == 1 {
+			return precedence(e.Operands[0])
+		}
+		if e.Operator == OpOr {
+			return precOr
+		}
+		return precAnd
+	case *UnaryExpr:
+		if e.Operator == OpNot {
+			return precNot
+		}
+		return precCompare
+	case *TernaryExpr:
+		return precCompare
+	case *FullTextExpr:
+		if e.Func == TextSearchMatch {
+			return precCompare
+		}
+	case *RawExpr:
+		return precRaw
+	}
+	return precOperand
+}
+
+// operandSQL renders an operand of an operator of precedence parent, parenthesized
+// when it binds looser than the operator. Comparisons don't chain, so a comparison
+// operand of a comparison is parenthesized too.
+func operandSQL(parent int, operand Expr) (string, []any) {
+	sql, args := operand.ToSQL()
+	if prec := precedence(operand); prec < parent || prec == precCompare && parent == precCompare {
+		return "(" + sql + ")", args
+	}
+	return sql, args
 }
@@ -0,0 +1,237 @@
+package querybuilder
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"little-orm/internal/database/registry"
+	"reflect"
+	"strings"
+)
+
+// Querier runs queries; *sql.DB, *sql.Tx and *sql.Conn implement it
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// FindQuery loads models of type T, and the relations named by Preload
+type FindQuery[T any] struct {
+	reg      *registry.DBRegistry
+	builder  *SelectBuilder
+	dialect  Dialect
+	preloads *preloadNode
+}
+
+// Find creates a query loading models of type T, registered in the default registry,
+// e.g. Find[model.User]().Where(Eq("Name", "john")).Preload("Messages").All(ctx, db)
+func Find[T any]() *FindQuery[T] {
+	return FindWith[T](registry.GetDBRegistry())
+}
+
+// FindWith is Find for a model registered in reg
+func FindWith[T any](reg *registry.DBRegistry) *FindQuery[T] {
+	var model T
+	t := reflect.TypeOf(model)
+	if t == nil || t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("Find requires a struct type, got %T", model))
+	}
+	return &FindQuery[T]{
+		reg:      reg,
+		builder:  NewSelectBuilderWith(reg, model),
+		dialect:  DefaultDialect,
+		preloads: &preloadNode{model: t, children: map[string]*preloadNode{}},
+	}
+}
+
+// Where sets the WHERE clause of the query loading the models
+func (q *FindQuery[T]) Where(e Expr) *FindQuery[T] {
+	q.builder = q.builder.Where(e)
+	return q
+}
+
+// OrderBy adds an ORDER BY clause to the query loading the models
+func (q *FindQuery[T]) OrderBy(field string, sortOrder SortOrder) *FindQuery[T] {
+	q.builder = q.builder.OrderBy(field, sortOrder)
+	return q
+}
+
+// Limit sets the maximum number of models loaded
+func (q *FindQuery[T]) Limit(n int) *FindQuery[T] {
+	q.builder = q.builder.Limit(n)
+	return q
+}
+
+// Offset sets the number of models skipped
+func (q *FindQuery[T]) Offset(m int) *FindQuery[T] {
+	q.builder = q.builder.Offset(m)
+	return q
+}
+
+// Dialect sets the SQL dialect of every query run
+func (q *FindQuery[T]) Dialect(d Dialect) *FindQuery[T] {
+	q.dialect = d
+	return q
+}
+
+// Preload loads the relation at path (e.g. "Messages" or "Messages.Attachments") onto the
+// loaded models, with one query per relation whatever the number of models. The options
+// apply to the query loading the last relation of the path. Unknown relations panic.
+func (q *FindQuery[T]) Preload(path string, opts ...PreloadOption) *FindQuery[T] {
+	node := q.preloads
+	for _, name := range strings.Split(path, ".") {
+		node = node.child(q.reg, name)
+	}
+	for _, opt := range opts {
+		node.builder = opt(node.builder)
+	}
+	return q
+}
+
+// All runs the query and its preloads, and returns the loaded models
+func (q *FindQuery[T]) All(ctx context.Context, db Querier) ([]T, error) {
+	query, args := q.builder.Build()
+	models, err := queryModels(ctx, db, q.dialect, query, args, q.preloads.model, q.builder.tableMeta)
+	if err != nil {
+		return nil, err
+	}
+	if err := q.preloads.load(ctx, db, q.dialect, models); err != nil {
+		return nil, err
+	}
+
+	result := make([]T, len(models))
+	for i, m := range models {
+		result[i] = m.Elem().Interface().(T)
+	}
+	return result, nil
+}
+
+// PreloadOption customizes the query loading a preloaded relation
+type PreloadOption func(*SelectBuilder) *SelectBuilder
+
+// Where filters the preloaded models, e.g. Preload("Messages", Where(Like("Content", "%go%")))
+func Where(e Expr) PreloadOption {
+	return func(b *SelectBuilder) *SelectBuilder { return b.AndWhere(e) }
+}
+
+// OrderBy orders the preloaded models of each parent
+func OrderBy(field string, sortOrder SortOrder) PreloadOption {
+	return func(b *SelectBuilder) *SelectBuilder { return b.OrderBy(field, sortOrder) }
+}
+
+// run executes a query built with "?" placeholders in the given dialect.
+// Slice arguments, bound as a single argument by IN expressions, are expanded first.
+func run(ctx context.Context, db Querier, d Dialect, query string, args []any) (*sql.Rows, error) {
+	query, args, err := expandSliceArgs(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return db.QueryContext(ctx, Rebind(d, query), args...)
+}
+
+// queryModels runs a query selecting columns of t and scans every row into a new *t
+func queryModels(ctx context.Context, db Querier, d Dialect, query string, args []any, t reflect.Type, tableMeta registry.TableMeta) ([]reflect.Value, error) {
+	rows, err := run(ctx, db, d, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	cols := make([]registry.ColumnMeta, len(columns))
+	for i, name := range columns {
+		col, ok := tableMeta.ColumnByDBTag(name)
+		if !ok {
+			return nil, fmt.Errorf("column '%s' not found in table '%s'", name, tableMeta.TableName)
+		}
+		cols[i] = col
+	}
+
+	var models []reflect.Value
+	dest := make([]any, len(cols))
+	for rows.Next() {
+		m := reflect.New(t)
+		for i, col := range cols {
+			dest[i] = fieldByIndex(m.Elem(), col.Index).Addr().Interface()
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		models = append(models, m)
+	}
+	return models, rows.Err()
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex allocating nil embedded struct pointers
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// expandSliceArgs replaces each "?" bound to a slice with a parenthesized list of
+// placeholders, one per element. An empty slice becomes (NULL) after IN, matching nothing;
+// after NOT IN no list matches every row, so it is an error. Lists built with In and NotIn
+// are already expanded by the expression tree; this covers raw SQL and named parameters.
+func expandSliceArgs(query string, args []any) (string, []any, error) {
+	var sb strings.Builder
+	var expanded []any
+	n := 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '?' && n < len(args):
+			arg := args[n]
+			n++
+			if list, ok := sliceArg(arg); ok {
+				if len(list) == 0 {
+					if strings.HasSuffix(strings.ToUpper(strings.TrimRight(sb.String(), " ")), " NOT IN") {
+						return "", nil, fmt.Errorf("empty list bound to NOT IN at offset %d", i)
+					}
+					sb.WriteString("(NULL)")
+					continue
+				}
+				sb.WriteString("(?" + strings.Repeat(", ?", len(list)-1) + ")")
+				expanded = append(expanded, list...)
+				continue
+			}
+			expanded = append(expanded, arg)
+		}
+		sb.WriteByte(ch)
+	}
+	return sb.String(), append(expanded, args[min(n, len(args)):]...), nil
+}
+
+// sliceArg returns the elements of a slice argument; byte slices and
+// driver.Valuer implementations are single values
+func sliceArg(arg any) ([]any, bool) {
+	if _, ok := arg.(driver.Valuer); ok {
+		return nil, false
+	}
+	v := reflect.ValueOf(arg)
+	if (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || v.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+	list := make([]any, v.Len())
+	for i := range list {
+		list[i] = v.Index(i).Interface()
+	}
+	return list, true
+}
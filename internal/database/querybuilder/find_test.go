@@ -0,0 +1,236 @@
+package querybuilder
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"little-orm/internal/database/registry"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeDB is a database/sql connector answering queries with canned rows.
+// It records the queries it runs, so tests can check how many were needed.
+type fakeDB struct {
+	mu      sync.Mutex
+	results map[string]fakeResult
+	queries []string
+	args    [][]any
+}
+
+type fakeResult struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{results: make(map[string]fakeResult)}
+}
+
+// on sets the rows returned for query
+func (f *fakeDB) on(query string, columns []string, rows ...[]driver.Value) {
+	f.results[query] = fakeResult{columns: columns, rows: rows}
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return fakeDriver{f} }
+
+type fakeDriver struct{ db *fakeDB }
+
+func (d fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{d.db}, nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, fmt.Errorf("prepare not supported") }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, fmt.Errorf("transactions not supported") }
+
+func (c fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	values := make([]any, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	c.db.queries = append(c.db.queries, query)
+	c.db.args = append(c.db.args, values)
+
+	result, ok := c.db.results[query]
+	if !ok {
+		return nil, fmt.Errorf("unexpected query: %s", query)
+	}
+	return &fakeRows{fakeResult: result}, nil
+}
+
+type fakeRows struct {
+	fakeResult
+	next int
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+// Library models relate to each other in every supported way
+type Author struct {
+	ID      int      `db:"id,pk"`
+	Name    string   `db:"name"`
+	Posts   []Post   `rel:"has_many"`
+	Profile *Profile `rel:"has_one"`
+}
+
+type Post struct {
+	ID       int        `db:"id,pk"`
+	AuthorID int64      `db:"author_id"`
+	Title    string     `db:"title"`
+	Author   *Author    `rel:"belongs_to"`
+	Comments []*Comment `rel:"has_many"`
+	Tags     []Tag      `rel:"many2many,join=post_tags"`
+}
+
+type Comment struct {
+	ID     int    `db:"id,pk"`
+	PostID int    `db:"post_id"`
+	Body   string `db:"body"`
+}
+
+type Tag struct {
+	ID   int    `db:"id,pk"`
+	Name string `db:"name"`
+}
+
+type Profile struct {
+	ID       int    `db:"id,pk"`
+	AuthorID int    `db:"author_id"`
+	Bio      string `db:"bio"`
+}
+
+func newLibraryRegistry() *registry.DBRegistry {
+	reg := registry.New()
+	reg.MustRegister(Author{}, Post{}, Comment{}, Tag{}, Profile{})
+	return reg
+}
+
+func TestFind_All(t *testing.T) {
+	t.Parallel()
+
+	db := newFakeDB()
+	db.on("SELECT id, name FROM authors WHERE name LIKE ? ORDER BY name ASC LIMIT 10",
+		[]string{"id", "name"},
+		[]driver.Value{int64(1), "ann"},
+		[]driver.Value{int64(2), "bob"},
+	)
+
+	authors, err := FindWith[Author](newLibraryRegistry()).
+		Where(Like("Name", "%")).
+		OrderBy("name", "ASC").
+		Limit(10).
+		All(context.Background(), sql.OpenDB(db))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []Author{{ID: 1, Name: "ann"}, {ID: 2, Name: "bob"}}
+	if !reflect.DeepEqual(authors, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, authors)
+	}
+	if !reflect.DeepEqual(db.args[0], []any{"%"}) {
+		t.Errorf("Expected args [%%], got %v", db.args[0])
+	}
+}
+
+func TestFind_EmptyLists(t *testing.T) {
+	t.Parallel()
+
+	db := newFakeDB()
+	db.on("SELECT id, name FROM authors WHERE 1=1", []string{"id", "name"}, []driver.Value{int64(1), "ann"})
+	db.on("SELECT id, name FROM authors WHERE 1=0", []string{"id", "name"})
+
+	all, err := FindWith[Author](newLibraryRegistry()).Where(NotIn("ID", []int{})).All(context.Background(), sql.OpenDB(db))
+	if err != nil || len(all) != 1 {
+		t.Errorf("Expected NOT IN an empty list to match every author, got %v, %v", all, err)
+	}
+	none, err := FindWith[Author](newLibraryRegistry()).Where(In("ID", []int{})).All(context.Background(), sql.OpenDB(db))
+	if err != nil || len(none) != 0 {
+		t.Errorf("Expected IN an empty list to match no author, got %v, %v", none, err)
+	}
+}
+
+func TestFind_UnknownColumn(t *testing.T) {
+	t.Parallel()
+
+	db := newFakeDB()
+	db.on("SELECT id, name FROM authors", []string{"id", "nickname"})
+
+	_, err := FindWith[Author](newLibraryRegistry()).All(context.Background(), sql.OpenDB(db))
+	if err == nil || !strings.Contains(err.Error(), "column 'nickname' not found") {
+		t.Errorf("Expected unknown column error, got %v", err)
+	}
+}
+
+func TestFind_QueryError(t *testing.T) {
+	t.Parallel()
+
+	_, err := FindWith[Author](newLibraryRegistry()).All(context.Background(), sql.OpenDB(newFakeDB()))
+	if err == nil || !strings.Contains(err.Error(), "unexpected query") {
+		t.Errorf("Expected the driver error, got %v", err)
+	}
+}
+
+func TestFind_NonStruct(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic for a non-struct type")
+		}
+	}()
+	FindWith[*Author](newLibraryRegistry())
+}
+
+func TestExpandSliceArgs(t *testing.T) {
+	tests := []struct {
+		query         string
+		args          []any
+		expectedQuery string
+		expectedArgs  []any
+		expectedError string
+	}{
+		{"a = ? AND (b IN ?)", []any{1, []int{2, 3}}, "a = ? AND (b IN (?, ?))", []any{1, 2, 3}, ""},
+		{"b IN ? AND c = '?'", []any{[]string{}}, "b IN (NULL) AND c = '?'", nil, ""},
+		{"b not in ? AND c = '?'", []any{[]string{}}, "", nil, "empty list bound to NOT IN at offset 9"},
+		{"d = ?", []any{[]byte("x")}, "d = ?", []any{[]byte("x")}, ""},
+		{"e IN ? AND f = ?", []any{[]any{"x"}, 4}, "e IN (?) AND f = ?", []any{"x", 4}, ""},
+	}
+
+	for _, tt := range tests {
+		query, args, err := expandSliceArgs(tt.query, tt.args)
+		if tt.expectedError != "" {
+			if err == nil || err.Error() != tt.expectedError {
+				t.Errorf("%s: expected error %q, got %v", tt.query, tt.expectedError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.query, err)
+		}
+		if query != tt.expectedQuery {
+			t.Errorf("Expected query %s, got %s", tt.expectedQuery, query)
+		}
+		if !reflect.DeepEqual(args, tt.expectedArgs) {
+			t.Errorf("%s: expected args %#v, got %#v", tt.query, tt.expectedArgs, args)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package querybuilder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// QueryFingerprint identifies the shape of a query independently of its argument values
+type QueryFingerprint struct {
+	// Normalized is the query with literals and placeholders replaced by "?" (a unary minus
+	// included), IN lists collapsed to "IN (...)", keywords upper-cased, comments removed
+	// and whitespace collapsed
+	Normalized string
+	// Hash is a short, stable hex digest of Normalized, suitable as a metric label
+	Hash string
+}
+
+func (f QueryFingerprint) String() string { return f.Hash }
+
+// fingerprintHashLen is the number of hex characters kept from the SHA-256 digest
+const fingerprintHashLen = 16
+
+// inListPattern matches a normalized IN list, written with or without a space before it:
+// placeholders, a single slice placeholder, or the (NULL) of an empty list
+var inListPattern = regexp.MustCompile(`\bIN ?(?:\((?:\?, )*\?\)|\(NULL\)|\?)`)
+
+// sqlKeywords are upper-cased by normalizeSQL, so that keyword case doesn't change the shape
+var sqlKeywords = map[string]bool{
+	"ALL": true, "AND": true, "AS": true, "ASC": true, "BETWEEN": true, "BY": true, "CASE": true,
+	"CROSS": true, "DELETE": true, "DESC": true, "DISTINCT": true, "ELSE": true, "END": true,
+	"EXISTS": true, "FALSE": true, "FROM": true, "FULL": true, "GROUP": true, "HAVING": true,
+	"ILIKE": true, "IN": true, "INNER": true, "INSERT": true, "INTO": true, "IS": true, "JOIN": true,
+	"LEFT": true, "LIKE": true, "LIMIT": true, "NOT": true, "NULL": true, "OFFSET": true, "ON": true,
+	"OR": true, "ORDER": true, "OUTER": true, "RETURNING": true, "RIGHT": true, "SELECT": true,
+	"SET": true, "THEN": true, "TRUE": true, "UNION": true, "UPDATE": true, "VALUES": true,
+	"WHEN": true, "WHERE": true,
+}
+
+// Fingerprint normalizes a SQL query, written by hand or produced by a builder in any dialect,
+// so that queries differing only in values, placeholder style or IN-list length share an identity
+func Fingerprint(query string) QueryFingerprint {
+	normalized := inListPattern.ReplaceAllStringFunc(normalizeSQL(query), func(m string) string {
+		return m[:2] + " (...)"
+	})
+	sum := sha256.Sum256([]byte(normalized))
+	return QueryFingerprint{Normalized: normalized, Hash: hex.EncodeToString(sum[:])[:fingerprintHashLen]}
+}
+
+// Fingerprint returns the fingerprint of the query built by b
+func (b *SelectBuilder) Fingerprint() QueryFingerprint {
+	query, _ := b.Build()
+	return Fingerprint(query)
+}
+
+// normalizeSQL replaces literals and placeholders with "?", upper-cases keywords,
+// drops comments and collapses whitespace
+func normalizeSQL(query string) string {
+	var sb strings.Builder
+	space := false
+	write := func(s string) {
+		if space && sb.Len() > 0 && !strings.HasSuffix(sb.String(), "(") && s != ")" && s != "," {
+			sb.WriteByte(' ')
+		}
+		// always follow a comma by a single space
+		space = s == ","
+		sb.WriteString(s)
+	}
+	// value writes the "?" of a literal or placeholder, folding a unary minus into it.
+	// The space before the minus is kept, so b=-1 gives b=? like b=1.
+	value := func() {
+		if out, ok := strings.CutSuffix(sb.String(), "-"); ok {
+			if before := strings.TrimRight(out, " "); unaryMinus(before) {
+				sb.Reset()
+				sb.WriteString(before)
+				space = len(before) < len(out)
+			}
+		}
+		write("?")
+	}
+
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			space = true
+		case ch == '-' && strings.HasPrefix(query[i:], "--"):
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+			space = true
+		case ch == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				i = len(query)
+			} else {
+				i += end + 3
+			}
+			space = true
+		case ch == '\'':
+			// string literal, '' is an escaped quote
+			for i++; i < len(query); i++ {
+				if query[i] == '\'' {
+					if i+1 < len(query) && query[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			value()
+		case ch == '"':
+			// quoted identifier, kept as is
+			start := i
+			for i++; i < len(query) && query[i] != '"'; i++ {
+			}
+			write(query[start:min(i+1, len(query))])
+		case ch == '?':
+			value()
+		case ch == '$' && i+1 < len(query) && isDigit(query[i+1]):
+			for i+1 < len(query) && isDigit(query[i+1]) {
+				i++
+			}
+			value()
+		case isDigit(ch):
+			for i+1 < len(query) && (isDigit(query[i+1]) || query[i+1] == '.') {
+				i++
+			}
+			// exponent, e.g. 1e5 or 2.5E-3
+			if j := i + 1; j < len(query) && (query[j] == 'e' || query[j] == 'E') {
+				k := j + 1
+				if k < len(query) && (query[k] == '+' || query[k] == '-') {
+					k++
+				}
+				if k < len(query) && isDigit(query[k]) {
+					for i = k; i+1 < len(query) && isDigit(query[i+1]); i++ {
+					}
+				}
+			}
+			value()
+		case isLetter(ch) || ch == '_':
+			// identifier or keyword, digits included (e.g. col1)
+			start := i
+			for i+1 < len(query) && (isLetter(query[i+1]) || isDigit(query[i+1]) || query[i+1] == '_') {
+				i++
+			}
+			word := query[start : i+1]
+			if upper := strings.ToUpper(word); sqlKeywords[upper] {
+				word = upper
+			}
+			write(word)
+		default:
+			write(string(ch))
+		}
+	}
+	return sb.String()
+}
+
+// unaryMinus reports whether a "-" following out is a sign rather than a subtraction:
+// it starts the query or follows an operator, an opening parenthesis, a comma or a keyword
+func unaryMinus(out string) bool {
+	if out == "" || strings.ContainsRune("(,=<>!+-*/%", rune(out[len(out)-1])) {
+		return true
+	}
+	word := out[strings.LastIndexAny(out, " (,.")+1:]
+	return sqlKeywords[word]
+}
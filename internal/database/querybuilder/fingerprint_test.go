@@ -0,0 +1,89 @@
+package querybuilder
+
+import (
+	"little-orm/internal/model"
+	"testing"
+)
+
+func TestFingerprint_Normalize(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"SELECT id FROM users WHERE id = 42", "SELECT id FROM users WHERE id = ?"},
+		{"SELECT id\n  FROM users\n WHERE name = 'O''Brien'  -- by name\n", "SELECT id FROM users WHERE name = ?"},
+		{"SELECT * FROM users WHERE id IN (1, 2, 3)", "SELECT * FROM users WHERE id IN (...)"},
+		{"SELECT * FROM users WHERE id in ( $1,$2 ) /* hint */ LIMIT 10", "SELECT * FROM users WHERE id IN (...) LIMIT ?"},
+		{"SELECT * FROM users WHERE (id NOT IN ?)", "SELECT * FROM users WHERE (id NOT IN (...))"},
+		{`SELECT col1, "weird 1" FROM t2 WHERE x > -1.5`, `SELECT col1, "weird 1" FROM t2 WHERE x > ?`},
+		{"select Name from users where id between -$1 and - 5 or (-?) in (-1, 2)", "SELECT Name FROM users WHERE id BETWEEN ? AND ? OR (?) IN (...)"},
+		{"SELECT a - 1, b-? FROM t WHERE c = x.d -1", "SELECT a - ?, b-? FROM t WHERE c = x.d -?"},
+	}
+
+	for _, tt := range tests {
+		if got := Fingerprint(tt.query).Normalized; got != tt.expected {
+			t.Errorf("Expected %q to normalize to:\n%s\nGot:\n%s", tt.query, tt.expected, got)
+		}
+	}
+}
+
+func TestFingerprint_SameShape(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"SELECT id FROM t WHERE id IN(?,?)", "SELECT id FROM t WHERE id IN (...)"},
+		{"SELECT id FROM t WHERE id IN (?, ?, ?)", "SELECT id FROM t WHERE id IN (...)"},
+		{"SELECT id FROM t WHERE id in (null)", "SELECT id FROM t WHERE id IN (...)"},
+		{"SELECT id FROM t WHERE id IN?", "SELECT id FROM t WHERE id IN (...)"},
+		{"SELECT id FROM t WHERE x > 1e5", "SELECT id FROM t WHERE x > ?"},
+		{"SELECT id FROM t WHERE x > 2.5E-3", "SELECT id FROM t WHERE x > ?"},
+		{"SELECT id FROM t WHERE x > -1e+5", "SELECT id FROM t WHERE x > ?"},
+		{"SELECT id FROM t WHERE b=-1", "SELECT id FROM t WHERE b=?"},
+		{"SELECT id FROM t WHERE b=1", "SELECT id FROM t WHERE b=?"},
+		{"SELECT id FROM t WHERE (-1)", "SELECT id FROM t WHERE (?)"},
+	}
+
+	for _, tt := range tests {
+		if got := Fingerprint(tt.query).Normalized; got != tt.expected {
+			t.Errorf("Expected %q to normalize to:\n%s\nGot:\n%s", tt.query, tt.expected, got)
+		}
+	}
+}
+
+func TestFingerprint_StableAcrossValues(t *testing.T) {
+	a := Fingerprint("SELECT id FROM users WHERE id IN (?, ?) AND name = ?")
+	b := Fingerprint("SELECT id FROM users WHERE id IN (?, ?) OR name = ?")
+	c := Fingerprint("select id from users where id in ($1, $2, $3, $4) and name = 'x'")
+
+	if a != c {
+		t.Errorf("Expected equal fingerprints, got %+v and %+v", a, c)
+	}
+	if a.Hash == b.Hash || a.Normalized == b.Normalized {
+		t.Errorf("Expected AND and OR queries to have different shapes, got %+v and %+v", a, b)
+	}
+	if len(a.Hash) != fingerprintHashLen {
+		t.Errorf("Expected hash of length %d, got %q", fingerprintHashLen, a.Hash)
+	}
+}
+
+func TestSelectBuilder_Fingerprint(t *testing.T) {
+	setupTestRegistry()
+
+	base := NewSelectBuilder(model.User{}).Select("ID").Immutable()
+	a := base.Where(And(In("ID", []int{1, 2, 3}), Eq("Name", "john"))).Limit(10).Fingerprint()
+	b := base.Where(And(In("ID", []int{7}), Eq("Name", "jane"))).Limit(50).Dialect(Postgres).Fingerprint()
+	c := base.Where(Eq("Name", "jane")).Fingerprint()
+
+	if a != b {
+		t.Errorf("Expected equal fingerprints, got %+v and %+v", a, b)
+	}
+	if a.Hash == c.Hash {
+		t.Errorf("Expected different hashes for different shapes, got %s", a.Hash)
+	}
+
+	expected := "SELECT id FROM users WHERE id IN (...) AND name = ? LIMIT ?"
+	if a.Normalized != expected {
+		t.Errorf("Expected normalized query:\n%s\nGot:\n%s", expected, a.Normalized)
+	}
+}
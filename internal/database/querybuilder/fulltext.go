@@ -0,0 +1,99 @@
+package querybuilder
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// DefaultTextSearchConfig is the text search configuration used when none is set
+const DefaultTextSearchConfig = "english"
+
+var textSearchConfigPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// FullTextExpr is a PostgreSQL full-text search expression over a column
+type FullTextExpr struct {
+	Func   TextSearchFunc
+	Column Expr
+	Query  Expr
+	Config string
+	Mode   TsQueryMode
+}
+
+// Match builds `to_tsvector(config, column) @@ plainto_tsquery(config, query)`
+func Match(column Expr, query string) *FullTextExpr {
+	return newFullText(TextSearchMatch, column, query)
+}
+
+// Rank builds `ts_rank(to_tsvector(config, column), plainto_tsquery(config, query))`
+func Rank(column Expr, query string) *FullTextExpr {
+	return newFullText(TextSearchRank, column, query)
+}
+
+// Headline builds `ts_headline(config, column, plainto_tsquery(config, query))`
+func Headline(column Expr, query string) *FullTextExpr {
+	return newFullText(TextSearchHeadline, column, query)
+}
+
+func newFullText(fn TextSearchFunc, column Expr, query string) *FullTextExpr {
+	return &FullTextExpr{
+		Func:   fn,
+		Column: column,
+		Query:  L(query),
+		Config: DefaultTextSearchConfig,
+		Mode:   PlainQuery,
+	}
+}
+
+// Language returns a copy of f using the text search configuration (e.g. "simple", "english")
+func (f *FullTextExpr) Language(config string) *FullTextExpr {
+	cp := *f
+	cp.Config = config
+	return &cp
+}
+
+// WebSearch returns a copy of f parsing the query with websearch_to_tsquery instead of plainto_tsquery
+func (f *FullTextExpr) WebSearch() *FullTextExpr {
+	cp := *f
+	cp.Mode = WebSearchQuery
+	return &cp
+}
+
+func (f *FullTextExpr) ToSQL() (string, []any) {
+	if f.Column == nil || f.Query == nil {
+		panic("Full-text Expression don't have enough Column or Query")
+	}
+	if err := validateTextSearch(f); err != nil {
+		panic(err.Error())
+	}
+
+	colSQL, colArgs := f.Column.ToSQL()
+	querySQL, queryArgs := f.Query.ToSQL()
+	args := append(colArgs, queryArgs...)
+
+	config := "'" + f.Config + "'"
+	tsQuery := fmt.Sprintf("%s(%s, %s)", f.Mode, config, querySQL)
+
+	switch f.Func {
+	case TextSearchMatch:
+		return fmt.Sprintf("to_tsvector(%s, %s) %s %s", config, colSQL, OpMatch, tsQuery), args
+	case TextSearchRank:
+		return fmt.Sprintf("ts_rank(to_tsvector(%s, %s), %s)", config, colSQL, tsQuery), args
+	case TextSearchHeadline:
+		return fmt.Sprintf("ts_headline(%s, %s, %s)", config, colSQL, tsQuery), args
+	default:
+		panic("unsupported full-text function: " + f.Func)
+	}
+}
+
+// validateTextSearch checks the parts of a full-text expression that are rendered verbatim
+func validateTextSearch(f *FullTextExpr) error {
+	if !textSearchConfigPattern.MatchString(f.Config) {
+		return fmt.Errorf("invalid text search configuration '%s'", f.Config)
+	}
+	switch f.Mode {
+	case PlainQuery, WebSearchQuery:
+	default:
+		return fmt.Errorf("unsupported tsquery mode '%s'", f.Mode)
+	}
+	return nil
+}
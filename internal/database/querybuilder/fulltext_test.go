@@ -0,0 +1,125 @@
+package querybuilder
+
+import (
+	"little-orm/internal/model"
+	"strings"
+	"testing"
+)
+
+func TestSelectBuilder_Where_Match(t *testing.T) {
+	setupTestRegistry()
+
+	builder := NewSelectBuilder(model.Message{})
+	query, args := builder.
+		Select("ID", "Content").
+		Where(Match(C("Content"), "hello world")).
+		Dialect(Postgres).
+		Build()
+
+	expected := "SELECT id, content FROM messages WHERE to_tsvector('english', content) @@ plainto_tsquery('english', $1)"
+	if query != expected {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expected, query)
+	}
+
+	if len(args) != 1 || args[0] != "hello world" {
+		t.Errorf("Expected args ['hello world'], got %v", args)
+	}
+}
+
+func TestSelectBuilder_Where_Match_WebSearchAndLanguage(t *testing.T) {
+	setupTestRegistry()
+
+	builder := NewSelectBuilder(model.Message{})
+	query, _ := builder.
+		Where(Match(C("Content"), `"hello world" -spam`).Language("simple").WebSearch()).
+		Build()
+
+	if !strings.Contains(query, "WHERE to_tsvector('simple', content) @@ websearch_to_tsquery('simple', ?)") {
+		t.Errorf("Expected websearch match with simple config, got: %s", query)
+	}
+}
+
+func TestFullTextExpr_OptionsReturnCopies(t *testing.T) {
+	match := Match(C("Content"), "hello")
+	simple := match.Language("simple")
+	web := match.WebSearch()
+
+	if match.Config != DefaultTextSearchConfig || match.Mode != PlainQuery {
+		t.Errorf("Expected the original expression to be unchanged, got %+v", match)
+	}
+	if simple.Config != "simple" || simple.Mode != PlainQuery {
+		t.Errorf("Expected only the language to change, got %+v", simple)
+	}
+	if web.Config != DefaultTextSearchConfig || web.Mode != WebSearchQuery {
+		t.Errorf("Expected only the mode to change, got %+v", web)
+	}
+}
+
+func TestSelectBuilder_RankAndHeadline(t *testing.T) {
+	setupTestRegistry()
+
+	builder := NewSelectBuilder(model.Message{})
+	query, args := builder.
+		Select("ID").
+		SelectExpr(
+			As(Headline(C("Content"), "hello"), "snippet"),
+			As(Rank(C("Content"), "hello"), "rank"),
+		).
+		Where(Match(C("Content"), "hello")).
+		OrderByExpr(Rank(C("Content"), "hello"), Descending).
+		Limit(10).
+		Dialect(Postgres).
+		Build()
+
+	expected := "SELECT id, " +
+		"ts_headline('english', content, plainto_tsquery('english', $1)) AS snippet, " +
+		"ts_rank(to_tsvector('english', content), plainto_tsquery('english', $2)) AS rank " +
+		"FROM messages " +
+		"WHERE to_tsvector('english', content) @@ plainto_tsquery('english', $3) " +
+		"ORDER BY ts_rank(to_tsvector('english', content), plainto_tsquery('english', $4)) DESC " +
+		"LIMIT 10"
+	if query != expected {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expected, query)
+	}
+
+	if len(args) != 4 {
+		t.Errorf("Expected 4 args, got %d: %v", len(args), args)
+	}
+}
+
+func TestSelectBuilder_Where_Match_InvalidColumn(t *testing.T) {
+	setupTestRegistry()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic for invalid column, but didn't panic")
+		}
+	}()
+
+	builder := NewSelectBuilder(model.Message{})
+	builder.Where(Match(C("Body"), "hello"))
+}
+
+func TestSelectBuilder_Where_Match_InvalidLanguage(t *testing.T) {
+	setupTestRegistry()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic for invalid text search configuration, but didn't panic")
+		} else if !strings.Contains(r.(string), "invalid text search configuration") {
+			t.Errorf("Unexpected panic message: %v", r)
+		}
+	}()
+
+	builder := NewSelectBuilder(model.Message{})
+	builder.Where(Match(C("Content"), "hello").Language("english'); DROP TABLE users; --"))
+}
+
+func TestRebind_Postgres(t *testing.T) {
+	query := Rebind(Postgres, "SELECT id FROM users WHERE name = ? AND email LIKE '%?%' AND id > ?")
+
+	expected := "SELECT id FROM users WHERE name = $1 AND email LIKE '%?%' AND id > $2"
+	if query != expected {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expected, query)
+	}
+}
@@ -1,11 +1,14 @@
 package querybuilder
 
+import "fmt"
+
 // Basic helper
-func C(name string) Expr             { return &ColumnExpr{Name: name} }
-func L(val any) Expr                 { return &LiteralExpr{Value: val} }
-func U(op Op, operand Expr) Expr     { return &UnaryExpr{Operator: op, Operand: operand} }
-func B(op Op, left, right Expr) Expr { return &BinaryExpr{Operator: op, Left: left, Right: right} }
-func T(expr, low, high Expr) Expr    { return &TernaryExpr{Expr: expr, Low: low, High: high} }
+func C(name string) Expr              { return &ColumnExpr{Name: name} }
+func L(val any) Expr                  { return &LiteralExpr{Value: val} }
+func U(op Op, operand Expr) Expr      { return &UnaryExpr{Operator: op, Operand: operand} }
+func B(op Op, left, right Expr) Expr  { return &BinaryExpr{Operator: op, Left: left, Right: right} }
+func T(expr, low, high Expr) Expr     { return &TernaryExpr{Expr: expr, Low: low, High: high} }
+func As(expr Expr, alias string) Expr { return &AliasExpr{Expr: expr, Alias: alias} }
 
 // Logical helper
 func And(exprs ...Expr) Expr {
@@ -29,3 +32,65 @@ func Or(exprs ...Expr) Expr {
 	}
 	return e
 }
+
+// Field is a column reference accepted by Like: a Go field name (e.g. "Name") or a
+// string column handle
+type Field[T any] interface {
+	string | Column[T]
+}
+
+// Col returns an untyped column handle for method chaining, e.g. Col("ID").Gt(10)
+func Col(name string) Column[any] { return NewColumn[any](name) }
+
+// Comparison helper, field is a Go field name. Typed column handles have the same
+// comparisons as methods (e.g. columns.UserCols.ID.Gt(10)), where the value takes the
+// column's type; a helper accepting both would infer it from the value instead.
+func Eq[T any](field string, v T) Expr  { return B(OpEq, C(field), L(v)) }
+func Neq[T any](field string, v T) Expr { return B(OpNEq, C(field), L(v)) }
+func Gt[T any](field string, v T) Expr  { return B(OpGt, C(field), L(v)) }
+func Lt[T any](field string, v T) Expr  { return B(OpLt, C(field), L(v)) }
+func Gte[T any](field string, v T) Expr { return B(OpGte, C(field), L(v)) }
+func Lte[T any](field string, v T) Expr { return B(OpLte, C(field), L(v)) }
+
+func Like[F Field[string]](field F, pattern string) Expr {
+	return B(OpLike, fieldExpr(field), L(pattern))
+}
+
+func In[T any](field string, values []T) Expr {
+	return B(OpIn, C(field), L(values))
+}
+
+func NotIn[T any](field string, values []T) Expr {
+	return B(OpNIn, C(field), L(values))
+}
+
+func Between[T any](field string, low, high T) Expr {
+	return &TernaryExpr{Expr: C(field), Low: L(low), High: L(high)}
+}
+
+func NotBetween[T any](field string, low, high T) Expr {
+	return Not(Between(field, low, high))
+}
+
+// AnyField is a column reference whose value type doesn't matter: a Go field name or a
+// typed column handle of any type (every Column[T] has the underlying type struct{ field string })
+type AnyField interface {
+	string | ~struct{ field string }
+}
+
+// Null helper, field is a Go field name or a column handle
+func IsNull[F AnyField](field F) Expr    { return U(OpIsNull, fieldExpr(field)) }
+func IsNotNull[F AnyField](field F) Expr { return U(OpIsNNull, fieldExpr(field)) }
+
+func Not(e Expr) Expr { return U(OpNot, e) }
+
+func fieldExpr(field any) Expr {
+	switch f := field.(type) {
+	case string:
+		return C(f)
+	case interface{ Expr() Expr }:
+		return f.Expr()
+	default:
+		panic(fmt.Sprintf("unsupported field reference of type %T", field))
+	}
+}
@@ -0,0 +1,93 @@
+package querybuilder
+
+import (
+	"little-orm/internal/model"
+	"testing"
+)
+
+func TestHelpers_ToSQL(t *testing.T) {
+	setupTestRegistry()
+
+	testCases := []struct {
+		name         string
+		expr         Expr
+		expectedSQL  string
+		expectedArgs int
+	}{
+		{name: "Eq", expr: Eq("ID", 1), expectedSQL: "id = ?", expectedArgs: 1},
+		{name: "Neq", expr: Neq("Name", "Admin"), expectedSQL: "name != ?", expectedArgs: 1},
+		{name: "Gt", expr: Gt("ID", 10), expectedSQL: "id > ?", expectedArgs: 1},
+		{name: "Lt", expr: Lt("ID", 10), expectedSQL: "id < ?", expectedArgs: 1},
+		{name: "Gte", expr: Gte("ID", 10), expectedSQL: "id >= ?", expectedArgs: 1},
+		{name: "Lte", expr: Lte("ID", 10), expectedSQL: "id <= ?", expectedArgs: 1},
+		{name: "Like", expr: Like("Name", "%John%"), expectedSQL: "name LIKE ?", expectedArgs: 1},
+		{name: "In", expr: In("ID", []int{1, 2, 3}), expectedSQL: "id IN (?, ?, ?)", expectedArgs: 3},
+		{name: "NotIn", expr: NotIn("ID", []int{4, 5}), expectedSQL: "id NOT IN (?, ?)", expectedArgs: 2},
+		{name: "Empty In", expr: In("ID", []int{}), expectedSQL: "1=0", expectedArgs: 0},
+		{name: "Empty NotIn", expr: And(NotIn("ID", []string{}), IsNull("Email")), expectedSQL: "1=1 AND email IS NULL", expectedArgs: 0},
+		{name: "Between", expr: Between("ID", 10, 100), expectedSQL: "id BETWEEN ? AND ?", expectedArgs: 2},
+		{name: "NotBetween", expr: NotBetween("ID", 10, 100), expectedSQL: "NOT (id BETWEEN ? AND ?)", expectedArgs: 2},
+		{name: "IsNull", expr: IsNull("Email"), expectedSQL: "email IS NULL", expectedArgs: 0},
+		{name: "IsNotNull", expr: IsNotNull("Email"), expectedSQL: "email IS NOT NULL", expectedArgs: 0},
+		{name: "Not", expr: Not(Eq("Name", "Admin")), expectedSQL: "NOT (name = ?)", expectedArgs: 1},
+		{name: "Typed column", expr: NewColumn[int]("ID").Eq(7), expectedSQL: "id = ?", expectedArgs: 1},
+		{name: "Typed Like", expr: Like(NewColumn[string]("Name"), "%J%"), expectedSQL: "name LIKE ?", expectedArgs: 1},
+		{name: "Typed IsNull", expr: IsNull(NewColumn[string]("Email")), expectedSQL: "email IS NULL", expectedArgs: 0},
+		{name: "Col chaining", expr: Col("ID").Gt(10), expectedSQL: "id > ?", expectedArgs: 1},
+		{name: "Col NotIn", expr: Col("ID").NotIn(1, 2), expectedSQL: "id NOT IN (?, ?)", expectedArgs: 2},
+		{name: "Col NotBetween", expr: Col("ID").NotBetween(1, 2), expectedSQL: "NOT (id BETWEEN ? AND ?)", expectedArgs: 2},
+		{name: "Or in And", expr: And(Or(Eq("ID", 1), Eq("ID", 2)), IsNull("Email")), expectedSQL: "(id = ? OR id = ?) AND email IS NULL", expectedArgs: 2},
+		{name: "And in Or", expr: Or(And(Eq("ID", 1), IsNull("Email")), Eq("ID", 2)), expectedSQL: "id = ? AND email IS NULL OR id = ?", expectedArgs: 2},
+		{name: "And under IS NULL", expr: U(OpIsNull, And(Eq("ID", 1), Eq("Name", "x"))), expectedSQL: "(id = ? AND name = ?) IS NULL", expectedArgs: 2},
+		{name: "Or under BETWEEN", expr: T(Or(Eq("ID", 1), Eq("ID", 2)), L(false), L(true)), expectedSQL: "(id = ? OR id = ?) BETWEEN ? AND ?", expectedArgs: 4},
+		{name: "Comparison of a comparison", expr: B(OpEq, B(OpEq, C("ID"), L(1)), L(true)), expectedSQL: "(id = ?) = ?", expectedArgs: 2},
+		{name: "Rank compared", expr: B(OpGt, Rank(C("Name"), "x"), L(0.1)), expectedSQL: "ts_rank(to_tsvector('english', name), plainto_tsquery('english', ?)) > ?", expectedArgs: 2},
+		{name: "Comparison under IS NOT NULL", expr: U(OpIsNNull, Gt("ID", 1)), expectedSQL: "(id > ?) IS NOT NULL", expectedArgs: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, args := NewSelectBuilder(model.User{}).Select("ID").Where(tc.expr).Build()
+
+			expected := "SELECT id FROM users WHERE " + tc.expectedSQL
+			if query != expected {
+				t.Errorf("Expected query:\n%s\nGot:\n%s", expected, query)
+			}
+
+			if len(args) != tc.expectedArgs {
+				t.Errorf("Expected %d args, got %d: %v", tc.expectedArgs, len(args), args)
+			}
+		})
+	}
+}
+
+func TestHelpers_ReadmeComplexQuery(t *testing.T) {
+	setupTestRegistry()
+
+	query, args := NewSelectBuilder(model.User{}).
+		Select("ID", "Name", "Email").
+		Where(And(Gt("ID", 10), Like("Name", "%John%"))).
+		OrderBy("ID", Descending).
+		Limit(5).
+		Offset(10).
+		Build()
+
+	expected := "SELECT id, name, email FROM users WHERE id > ? AND name LIKE ? ORDER BY ID DESC LIMIT 5 OFFSET 10"
+	if query != expected {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expected, query)
+	}
+
+	if len(args) != 2 || args[0] != 10 || args[1] != "%John%" {
+		t.Errorf("Expected args [10 %%John%%], got %v", args)
+	}
+}
+
+func TestHelpers_UnsupportedFieldReference_ShouldPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic for unsupported field reference, but didn't panic")
+		}
+	}()
+
+	fieldExpr(42)
+}
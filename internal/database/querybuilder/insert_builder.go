@@ -12,10 +12,14 @@ type InsertBuilder struct {
 	tableMeta registry.TableMeta
 }
 
-// NewInsertBuilder creates a new INSERT query builder for the given model
+// NewInsertBuilder creates a new INSERT query builder for the given model,
+// registered in the default registry
 func NewInsertBuilder(model any) *InsertBuilder {
-	// Get table registry and table meta
-	reg := registry.GetDBRegistry()
+	return NewInsertBuilderWith(registry.GetDBRegistry(), model)
+}
+
+// NewInsertBuilderWith creates a new INSERT query builder for a model registered in reg
+func NewInsertBuilderWith(reg *registry.DBRegistry, model any) *InsertBuilder {
 	tableMeta := reg.GetTableMeta(model)
 
 	return &InsertBuilder{
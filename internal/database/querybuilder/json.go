@@ -0,0 +1,505 @@
+package querybuilder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"little-orm/internal/database/registry"
+	"sort"
+)
+
+// Limits applied by DecodeExpr when DecodeOptions leaves them unset
+const (
+	DefaultMaxDepth = 32
+	DefaultMaxNodes = 256
+	DefaultMaxBytes = 64 << 10
+)
+
+// DecodeOptions bounds the expression trees accepted by DecodeExpr
+type DecodeOptions struct {
+	MaxDepth int
+	MaxNodes int
+	MaxBytes int
+}
+
+// JSON node type names
+const (
+	jsonColumn   = "column"
+	jsonLiteral  = "literal"
+	jsonUnary    = "unary"
+	jsonBinary   = "binary"
+	jsonTernary  = "between"
+	jsonLogical  = "logical"
+	jsonFullText = "fulltext"
+	jsonAlias    = "alias"
+	jsonParam    = "param"
+)
+
+func (c *ColumnExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"type": jsonColumn, "name": c.Name})
+}
+
+func (l *LiteralExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"type": jsonLiteral, "value": l.Value})
+}
+
+func (u *UnaryExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"type": jsonUnary, "op": u.Operator, "operand": u.Operand})
+}
+
+func (b *BinaryExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"type": jsonBinary, "op": b.Operator, "left": b.Left, "right": b.Right})
+}
+
+func (b *TernaryExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"type": jsonTernary, "expr": b.Expr, "low": b.Low, "high": b.High})
+}
+
+func (l *LogicalExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"type": jsonLogical, "op": l.Operator, "operands": l.Operands})
+}
+
+func (f *FullTextExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"type":   jsonFullText,
+		"func":   f.Func,
+		"column": f.Column,
+		"query":  f.Query,
+		"config": f.Config,
+		"mode":   f.Mode,
+	})
+}
+
+func (a *AliasExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"type": jsonAlias, "expr": a.Expr, "alias": a.Alias})
+}
+
+func (p *ParamExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"type": jsonParam, "name": p.Name})
+}
+
+// DecodeExpr strictly decodes a JSON expression tree: unknown node types, fields and
+// operators are rejected, as are operands of the wrong kind (e.g. a list under =, or a
+// column under AND), and the tree must fit within the limits of opts
+func DecodeExpr(data []byte, opts DecodeOptions) (Expr, error) {
+	d := &exprDecoder{opts: opts}
+	if d.opts.MaxDepth <= 0 {
+		d.opts.MaxDepth = DefaultMaxDepth
+	}
+	if d.opts.MaxNodes <= 0 {
+		d.opts.MaxNodes = DefaultMaxNodes
+	}
+	if d.opts.MaxBytes <= 0 {
+		d.opts.MaxBytes = DefaultMaxBytes
+	}
+	if len(data) > d.opts.MaxBytes {
+		return nil, fmt.Errorf("expression is %d bytes, limit is %d", len(data), d.opts.MaxBytes)
+	}
+	return d.decode(json.RawMessage(data), 1)
+}
+
+// DecodeFilter decodes a JSON expression and validates it against the columns of model.
+// The returned expression keeps Go field names and can be passed to SelectBuilder.Where.
+// Nodes that only make sense in a projection (aliases, ts_rank and ts_headline) are rejected.
+func DecodeFilter(model any, data []byte, opts DecodeOptions) (Expr, error) {
+	return DecodeFilterWith(registry.GetDBRegistry(), model, data, opts)
+}
+
+// DecodeFilterWith is DecodeFilter for a model registered in reg
+func DecodeFilterWith(reg *registry.DBRegistry, model any, data []byte, opts DecodeOptions) (Expr, error) {
+	tableMeta, err := reg.LookupTableMeta(model)
+	if err != nil {
+		return nil, err
+	}
+	expr, err := DecodeExpr(data, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPredicate(expr); err != nil {
+		return nil, err
+	}
+	validator := &ExprValidator{tableMeta: tableMeta}
+	if _, err := validator.Resolve(expr); err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+// checkPredicate rejects filter expressions that aren't boolean or contain projection-only nodes
+func checkPredicate(expr Expr) error {
+	var err error
+	Inspect(expr, func(e Expr) bool {
+		switch e := e.(type) {
+		case *AliasExpr:
+			err = fmt.Errorf("'%s' expression is not allowed in a filter", jsonAlias)
+		case *FullTextExpr:
+			if e.Func != TextSearchMatch {
+				err = fmt.Errorf("'%s' expression with function '%s' is not allowed in a filter", jsonFullText, e.Func)
+			}
+		}
+		return err == nil
+	})
+	if err == nil && !isPredicate(expr) {
+		err = fmt.Errorf("filter must be a boolean expression")
+	}
+	return err
+}
+
+type exprDecoder struct {
+	opts  DecodeOptions
+	nodes int
+}
+
+type nodeDecoder func(d *exprDecoder, fields map[string]json.RawMessage, depth int) (Expr, error)
+
+type nodeSpec struct {
+	fields []string
+	decode nodeDecoder
+}
+
+// nodeDecoders maps each JSON node type to its decoder and allowed fields
+var nodeDecoders map[string]nodeSpec
+
+func init() {
+	nodeDecoders = map[string]nodeSpec{
+		jsonColumn:   {[]string{"name"}, decodeColumn},
+		jsonLiteral:  {[]string{"value"}, decodeLiteral},
+		jsonUnary:    {[]string{"op", "operand"}, decodeUnary},
+		jsonBinary:   {[]string{"op", "left", "right"}, decodeBinary},
+		jsonTernary:  {[]string{"expr", "low", "high"}, decodeTernary},
+		jsonLogical:  {[]string{"op", "operands"}, decodeLogical},
+		jsonFullText: {[]string{"func", "column", "query", "config", "mode"}, decodeFullText},
+		jsonAlias:    {[]string{"expr", "alias"}, decodeAlias},
+		jsonParam:    {[]string{"name"}, decodeParam},
+	}
+}
+
+var (
+	unaryOps   = map[Op]bool{OpNot: true, OpIsNull: true, OpIsNNull: true}
+	binaryOps  = map[Op]bool{OpEq: true, OpNEq: true, OpGt: true, OpLt: true, OpGte: true, OpLte: true, OpLike: true, OpIn: true, OpNIn: true, OpAnd: true, OpOr: true}
+	logicalOps = map[Op]bool{OpAnd: true, OpOr: true}
+	textFuncs  = map[TextSearchFunc]bool{TextSearchMatch: true, TextSearchRank: true, TextSearchHeadline: true}
+)
+
+func (d *exprDecoder) decode(data json.RawMessage, depth int) (Expr, error) {
+	if depth > d.opts.MaxDepth {
+		return nil, fmt.Errorf("expression nesting exceeds depth %d", d.opts.MaxDepth)
+	}
+	d.nodes++
+	if d.nodes > d.opts.MaxNodes {
+		return nil, fmt.Errorf("expression exceeds %d nodes", d.opts.MaxNodes)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("expression node must be a JSON object")
+	}
+
+	var typ string
+	if err := json.Unmarshal(fields["type"], &typ); err != nil {
+		return nil, fmt.Errorf("expression node has no valid 'type'")
+	}
+	node, ok := nodeDecoders[typ]
+	if !ok {
+		return nil, fmt.Errorf("unknown expression type '%s'", typ)
+	}
+
+	allowed := map[string]bool{"type": true}
+	for _, f := range node.fields {
+		allowed[f] = true
+	}
+	unknown := []string{}
+	for name := range fields {
+		if !allowed[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown fields %v in '%s' expression", unknown, typ)
+	}
+
+	return node.decode(d, fields, depth)
+}
+
+// child decodes a required sub-expression
+func (d *exprDecoder) child(fields map[string]json.RawMessage, name string, depth int) (Expr, error) {
+	raw, ok := fields[name]
+	if !ok || string(raw) == "null" {
+		return nil, fmt.Errorf("missing '%s' sub-expression", name)
+	}
+	return d.decode(raw, depth+1)
+}
+
+func decodeString(fields map[string]json.RawMessage, name string) (string, error) {
+	var s string
+	if err := json.Unmarshal(fields[name], &s); err != nil {
+		return "", fmt.Errorf("'%s' must be a string", name)
+	}
+	return s, nil
+}
+
+func decodeColumn(_ *exprDecoder, fields map[string]json.RawMessage, _ int) (Expr, error) {
+	name, err := decodeString(fields, "name")
+	if err != nil {
+		return nil, err
+	}
+	return C(name), nil
+}
+
+func decodeLiteral(_ *exprDecoder, fields map[string]json.RawMessage, _ int) (Expr, error) {
+	raw, ok := fields["value"]
+	if !ok {
+		return nil, fmt.Errorf("missing literal 'value'")
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	value, err := literalValue(v, true)
+	if err != nil {
+		return nil, err
+	}
+	return L(value), nil
+}
+
+// literalValue converts a decoded JSON value into a scalar or a list of scalars
+func literalValue(v any, allowList bool) (any, error) {
+	switch v := v.(type) {
+	case nil, string, bool:
+		return v, nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		return v.Float64()
+	case []any:
+		if !allowList {
+			return nil, fmt.Errorf("nested lists are not allowed in literals")
+		}
+		values := make([]any, len(v))
+		for i, item := range v {
+			value, err := literalValue(item, false)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("unsupported literal value of type %T", v)
+	}
+}
+
+func decodeOp(fields map[string]json.RawMessage, allowed map[Op]bool) (Op, error) {
+	op, err := decodeString(fields, "op")
+	if err != nil {
+		return "", err
+	}
+	if !allowed[Op(op)] {
+		return "", fmt.Errorf("unsupported operator '%s'", op)
+	}
+	return Op(op), nil
+}
+
+func decodeUnary(d *exprDecoder, fields map[string]json.RawMessage, depth int) (Expr, error) {
+	op, err := decodeOp(fields, unaryOps)
+	if err != nil {
+		return nil, err
+	}
+	operand, err := d.child(fields, "operand", depth)
+	if err != nil {
+		return nil, err
+	}
+	if op == OpNot && !isPredicate(operand) {
+		return nil, fmt.Errorf("operand of '%s' must be a boolean expression", op)
+	}
+	if op != OpNot && !isValue(operand) {
+		return nil, fmt.Errorf("operand of '%s' must be a single value", op)
+	}
+	return U(op, operand), nil
+}
+
+func decodeBinary(d *exprDecoder, fields map[string]json.RawMessage, depth int) (Expr, error) {
+	op, err := decodeOp(fields, binaryOps)
+	if err != nil {
+		return nil, err
+	}
+	left, err := d.child(fields, "left", depth)
+	if err != nil {
+		return nil, err
+	}
+	right, err := d.child(fields, "right", depth)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBinaryOperands(op, left, right); err != nil {
+		return nil, err
+	}
+	return B(op, left, right), nil
+}
+
+// checkBinaryOperands checks the kind of the operands of a binary operator: boolean
+// expressions for AND/OR, a single value and a literal list for IN/NOT IN, and single
+// values for comparisons
+func checkBinaryOperands(op Op, left, right Expr) error {
+	switch op {
+	case OpAnd, OpOr:
+		if !isPredicate(left) || !isPredicate(right) {
+			return fmt.Errorf("operands of '%s' must be boolean expressions", op)
+		}
+	case OpIn, OpNIn:
+		if !isValue(left) || !isListLiteral(right) {
+			return fmt.Errorf("'%s' requires a single value on the left and a literal list on the right", op)
+		}
+	default:
+		if !isValue(left) || !isValue(right) {
+			return fmt.Errorf("operands of '%s' must be single values", op)
+		}
+	}
+	return nil
+}
+
+func decodeTernary(d *exprDecoder, fields map[string]json.RawMessage, depth int) (Expr, error) {
+	expr, err := d.child(fields, "expr", depth)
+	if err != nil {
+		return nil, err
+	}
+	low, err := d.child(fields, "low", depth)
+	if err != nil {
+		return nil, err
+	}
+	high, err := d.child(fields, "high", depth)
+	if err != nil {
+		return nil, err
+	}
+	if !isValue(expr) || !isValue(low) || !isValue(high) {
+		return nil, fmt.Errorf("operands of 'BETWEEN' must be single values")
+	}
+	return T(expr, low, high), nil
+}
+
+func decodeLogical(d *exprDecoder, fields map[string]json.RawMessage, depth int) (Expr, error) {
+	op, err := decodeOp(fields, logicalOps)
+	if err != nil {
+		return nil, err
+	}
+	var raws []json.RawMessage
+	if err := json.Unmarshal(fields["operands"], &raws); err != nil || len(raws) == 0 {
+		return nil, fmt.Errorf("'operands' must be a non-empty list")
+	}
+	operands := make([]Expr, len(raws))
+	for i, raw := range raws {
+		if operands[i], err = d.decode(raw, depth+1); err != nil {
+			return nil, err
+		}
+		if !isPredicate(operands[i]) {
+			return nil, fmt.Errorf("operands of '%s' must be boolean expressions", op)
+		}
+	}
+	return &LogicalExpr{Operator: op, Operands: operands}, nil
+}
+
+func decodeFullText(d *exprDecoder, fields map[string]json.RawMessage, depth int) (Expr, error) {
+	fn, err := decodeString(fields, "func")
+	if err != nil {
+		return nil, err
+	}
+	if !textFuncs[TextSearchFunc(fn)] {
+		return nil, fmt.Errorf("unsupported full-text function '%s'", fn)
+	}
+	column, err := d.child(fields, "column", depth)
+	if err != nil {
+		return nil, err
+	}
+	query, err := d.child(fields, "query", depth)
+	if err != nil {
+		return nil, err
+	}
+	if !isValue(column) || !isValue(query) {
+		return nil, fmt.Errorf("'column' and 'query' of a '%s' expression must be single values", jsonFullText)
+	}
+	f := &FullTextExpr{Func: TextSearchFunc(fn), Column: column, Query: query, Config: DefaultTextSearchConfig, Mode: PlainQuery}
+	if _, ok := fields["config"]; ok {
+		if f.Config, err = decodeString(fields, "config"); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := fields["mode"]; ok {
+		mode, err := decodeString(fields, "mode")
+		if err != nil {
+			return nil, err
+		}
+		f.Mode = TsQueryMode(mode)
+	}
+	if err := validateTextSearch(f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func decodeAlias(d *exprDecoder, fields map[string]json.RawMessage, depth int) (Expr, error) {
+	expr, err := d.child(fields, "expr", depth)
+	if err != nil {
+		return nil, err
+	}
+	alias, err := decodeString(fields, "alias")
+	if err != nil {
+		return nil, err
+	}
+	if !identifierPattern.MatchString(alias) {
+		return nil, fmt.Errorf("invalid alias '%s'", alias)
+	}
+	return As(expr, alias), nil
+}
+
+func decodeParam(d *exprDecoder, fields map[string]json.RawMessage, depth int) (Expr, error) {
+	name, err := decodeString(fields, "name")
+	if err != nil {
+		return nil, err
+	}
+	if !identifierPattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid parameter name '%s'", name)
+	}
+	return Param(name), nil
+}
+
+// isPredicate reports whether e evaluates to a boolean
+func isPredicate(e Expr) bool {
+	switch e := e.(type) {
+	case *BinaryExpr, *LogicalExpr, *UnaryExpr, *TernaryExpr:
+		return true
+	case *FullTextExpr:
+		return e.Func == TextSearchMatch
+	case *LiteralExpr:
+		_, ok := e.Value.(bool)
+		return ok
+	}
+	return false
+}
+
+// isValue reports whether e is a single value: a column, a scalar literal, a parameter,
+// or a ts_rank/ts_headline call
+func isValue(e Expr) bool {
+	switch e := e.(type) {
+	case *ColumnExpr, *ParamExpr:
+		return true
+	case *LiteralExpr:
+		return !isListLiteral(e)
+	case *FullTextExpr:
+		return e.Func != TextSearchMatch
+	}
+	return false
+}
+
+// isListLiteral reports whether e is a literal list of values
+func isListLiteral(e Expr) bool {
+	lit, ok := e.(*LiteralExpr)
+	if !ok {
+		return false
+	}
+	_, ok = lit.Value.([]any)
+	return ok
+}
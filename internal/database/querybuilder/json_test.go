@@ -0,0 +1,281 @@
+package querybuilder
+
+import (
+	"encoding/json"
+	"little-orm/internal/model"
+	"strings"
+	"testing"
+)
+
+func TestExprJSON_RoundTrip(t *testing.T) {
+	exprs := []Expr{
+		And(Gt("ID", 10), Like("Name", "%John%")),
+		Or(Not(IsNull("Email")), Between("ID", 1, 5)),
+		In("ID", []int{1, 2, 3}),
+		Simplify(And(Eq("ID", 1), Eq("Name", "a"), Eq("Email", "b"))),
+		Match(C("Content"), "hello").Language("simple").WebSearch(),
+		As(Rank(C("Content"), "hello"), "rank"),
+	}
+
+	for _, expr := range exprs {
+		data, err := json.Marshal(expr)
+		if err != nil {
+			t.Fatalf("Unexpected marshal error: %v", err)
+		}
+
+		decoded, err := DecodeExpr(data, DecodeOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected decode error for %s: %v", data, err)
+		}
+
+		expectedSQL, _ := expr.ToSQL()
+		gotSQL, _ := decoded.ToSQL()
+		if expectedSQL != gotSQL {
+			t.Errorf("Expected SQL %s after round trip, got %s (json: %s)", expectedSQL, gotSQL, data)
+		}
+	}
+}
+
+func TestExprJSON_Marshal(t *testing.T) {
+	data, err := json.Marshal(Eq("ID", 1))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `{"left":{"name":"ID","type":"column"},"op":"=","right":{"type":"literal","value":1},"type":"binary"}`
+	if string(data) != expected {
+		t.Errorf("Expected JSON:\n%s\nGot:\n%s", expected, data)
+	}
+}
+
+func TestDecodeExpr_LiteralValues(t *testing.T) {
+	expr, err := DecodeExpr([]byte(`{"type":"binary","op":"IN","left":{"type":"column","name":"ID"},"right":{"type":"literal","value":[1,2.5,"x",true,null]}}`), DecodeOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, values := expr.ToSQL()
+	if len(values) != 5 {
+		t.Fatalf("Expected 5 list values, got %#v", values)
+	}
+	if values[0] != int64(1) || values[1] != 2.5 || values[2] != "x" || values[3] != true || values[4] != nil {
+		t.Errorf("Unexpected literal values: %#v", values)
+	}
+}
+
+func TestDecodeExpr_Strict(t *testing.T) {
+	testCases := []struct {
+		name  string
+		json  string
+		opts  DecodeOptions
+		error string
+	}{
+		{
+			name:  "Unknown type",
+			json:  `{"type":"raw","sql":"1=1"}`,
+			error: "unknown expression type 'raw'",
+		},
+		{
+			name:  "Unknown operator",
+			json:  `{"type":"binary","op":"; DROP","left":{"type":"column","name":"ID"},"right":{"type":"literal","value":1}}`,
+			error: "unsupported operator '; DROP'",
+		},
+		{
+			name:  "Logical operator in unary",
+			json:  `{"type":"unary","op":"AND","operand":{"type":"column","name":"ID"}}`,
+			error: "unsupported operator 'AND'",
+		},
+		{
+			name:  "Unknown field",
+			json:  `{"type":"column","name":"ID","table":"users"}`,
+			error: "unknown fields [table] in 'column' expression",
+		},
+		{
+			name:  "Missing child",
+			json:  `{"type":"unary","op":"NOT"}`,
+			error: "missing 'operand' sub-expression",
+		},
+		{
+			name:  "Object literal",
+			json:  `{"type":"literal","value":{"a":1}}`,
+			error: "unsupported literal value",
+		},
+		{
+			name:  "Nested list literal",
+			json:  `{"type":"literal","value":[[1]]}`,
+			error: "nested lists are not allowed",
+		},
+		{
+			name:  "Not an object",
+			json:  `[1,2]`,
+			error: "expression node must be a JSON object",
+		},
+		{
+			name:  "Invalid text search config",
+			json:  `{"type":"fulltext","func":"match","column":{"type":"column","name":"Content"},"query":{"type":"literal","value":"x"},"config":"x'"}`,
+			error: "invalid text search configuration",
+		},
+		{
+			name:  "Depth limit",
+			json:  `{"type":"unary","op":"NOT","operand":{"type":"unary","op":"NOT","operand":{"type":"column","name":"ID"}}}`,
+			opts:  DecodeOptions{MaxDepth: 2},
+			error: "expression nesting exceeds depth 2",
+		},
+		{
+			name:  "Node limit",
+			json:  `{"type":"logical","op":"OR","operands":[{"type":"unary","op":"IS NULL","operand":{"type":"column","name":"ID"}}]}`,
+			opts:  DecodeOptions{MaxNodes: 2},
+			error: "expression exceeds 2 nodes",
+		},
+		{
+			name:  "List under =",
+			json:  `{"type":"binary","op":"=","left":{"type":"column","name":"ID"},"right":{"type":"literal","value":[1,2]}}`,
+			error: "operands of '=' must be single values",
+		},
+		{
+			name:  "List under LIKE",
+			json:  `{"type":"binary","op":"LIKE","left":{"type":"column","name":"Name"},"right":{"type":"literal","value":["a%"]}}`,
+			error: "operands of 'LIKE' must be single values",
+		},
+		{
+			name:  "Scalar under IN",
+			json:  `{"type":"binary","op":"IN","left":{"type":"column","name":"ID"},"right":{"type":"literal","value":5}}`,
+			error: "'IN' requires a single value on the left and a literal list on the right",
+		},
+		{
+			name:  "Column under NOT IN",
+			json:  `{"type":"binary","op":"NOT IN","left":{"type":"column","name":"ID"},"right":{"type":"column","name":"ID"}}`,
+			error: "'NOT IN' requires a single value on the left and a literal list on the right",
+		},
+		{
+			name:  "Comparison under =",
+			json:  `{"type":"binary","op":"=","left":{"type":"binary","op":"=","left":{"type":"column","name":"ID"},"right":{"type":"literal","value":1}},"right":{"type":"literal","value":2}}`,
+			error: "operands of '=' must be single values",
+		},
+		{
+			name:  "Columns under AND",
+			json:  `{"type":"binary","op":"AND","left":{"type":"column","name":"ID"},"right":{"type":"column","name":"Name"}}`,
+			error: "operands of 'AND' must be boolean expressions",
+		},
+		{
+			name:  "Column under logical OR",
+			json:  `{"type":"logical","op":"OR","operands":[{"type":"literal","value":true},{"type":"column","name":"ID"}]}`,
+			error: "operands of 'OR' must be boolean expressions",
+		},
+		{
+			name:  "Column under NOT",
+			json:  `{"type":"unary","op":"NOT","operand":{"type":"column","name":"ID"}}`,
+			error: "operand of 'NOT' must be a boolean expression",
+		},
+		{
+			name:  "AND under IS NULL",
+			json:  `{"type":"unary","op":"IS NULL","operand":{"type":"binary","op":"AND","left":{"type":"unary","op":"IS NULL","operand":{"type":"column","name":"ID"}},"right":{"type":"unary","op":"IS NULL","operand":{"type":"column","name":"Name"}}}}`,
+			error: "operand of 'IS NULL' must be a single value",
+		},
+		{
+			name:  "List under BETWEEN",
+			json:  `{"type":"between","expr":{"type":"column","name":"ID"},"low":{"type":"literal","value":[1]},"high":{"type":"literal","value":2}}`,
+			error: "operands of 'BETWEEN' must be single values",
+		},
+		{
+			name:  "Size limit",
+			json:  `{"type":"column","name":"ID"}`,
+			opts:  DecodeOptions{MaxBytes: 10},
+			error: "limit is 10",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := DecodeExpr([]byte(tc.json), tc.opts)
+			if err == nil {
+				t.Fatalf("Expected error containing %q, got nil", tc.error)
+			}
+			if !strings.Contains(err.Error(), tc.error) {
+				t.Errorf("Expected error containing %q, got %q", tc.error, err.Error())
+			}
+		})
+	}
+}
+
+func TestDecodeFilter(t *testing.T) {
+	setupTestRegistry()
+
+	filter, err := DecodeFilter(model.User{}, []byte(`{"type":"binary","op":"LIKE","left":{"type":"column","name":"Name"},"right":{"type":"literal","value":"J%"}}`), DecodeOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := NewSelectBuilder(model.User{}).Select("ID").Where(filter).Build()
+	if query != "SELECT id FROM users WHERE name LIKE ?" {
+		t.Errorf("Unexpected query: %s", query)
+	}
+	if len(args) != 1 || args[0] != "J%" {
+		t.Errorf("Expected args [J%%], got %v", args)
+	}
+}
+
+func TestDecodeFilterWith(t *testing.T) {
+	t.Parallel()
+
+	reg := newLibraryRegistry()
+	filter, err := DecodeFilterWith(reg, Post{}, []byte(`{"type":"binary","op":"=","left":{"type":"column","name":"AuthorID"},"right":{"type":"literal","value":1}}`), DecodeOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, _ := NewSelectBuilderWith(reg, Post{}).Select("ID").Where(filter).Build()
+	if query != "SELECT id FROM posts WHERE author_id = ?" {
+		t.Errorf("Unexpected query: %s", query)
+	}
+
+	if _, err := DecodeFilterWith(reg, model.User{}, []byte(`{"type":"column","name":"ID"}`), DecodeOptions{}); err == nil {
+		t.Error("Expected error for a model not registered in reg")
+	}
+}
+
+func TestDecodeFilter_InvalidColumn(t *testing.T) {
+	setupTestRegistry()
+
+	_, err := DecodeFilter(model.User{}, []byte(`{"type":"unary","op":"IS NULL","operand":{"type":"column","name":"Salary"}}`), DecodeOptions{})
+	if err == nil || !strings.Contains(err.Error(), "column 'Salary' not found") {
+		t.Errorf("Expected column not found error, got %v", err)
+	}
+}
+
+func TestDecodeFilter_ProjectionNodes(t *testing.T) {
+	setupTestRegistry()
+
+	tests := []struct {
+		data     string
+		expected string
+	}{
+		{`{"type":"alias","expr":{"type":"column","name":"ID"},"alias":"x"}`, "'alias' expression is not allowed"},
+		{`{"type":"binary","op":">","left":{"type":"fulltext","func":"ts_rank","column":{"type":"column","name":"Name"},` +
+			`"query":{"type":"literal","value":"go"}},"right":{"type":"literal","value":0.1}}`,
+			"function 'ts_rank' is not allowed"},
+		{`{"type":"column","name":"ID"}`, "filter must be a boolean expression"},
+	}
+	for _, tt := range tests {
+		_, err := DecodeFilter(model.User{}, []byte(tt.data), DecodeOptions{})
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("Expected error containing '%s', got %v", tt.expected, err)
+		}
+	}
+
+	filter, err := DecodeFilter(model.User{}, []byte(`{"type":"fulltext","func":"match","column":{"type":"column","name":"Name"},"query":{"type":"literal","value":"go"}}`), DecodeOptions{})
+	if err != nil || filter == nil {
+		t.Errorf("Expected a full-text match to be a valid filter, got %v", err)
+	}
+}
+
+func TestDecodeFilter_UnregisteredModel(t *testing.T) {
+	type unregistered struct {
+		ID int `db:"id"`
+	}
+
+	_, err := DecodeFilter(unregistered{}, []byte(`{"type":"column","name":"ID"}`), DecodeOptions{})
+	if err == nil || !strings.Contains(err.Error(), "is not registered") {
+		t.Errorf("Expected an unregistered model error, got %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package querybuilder
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// NamedParam is the argument a named parameter leaves in the args returned by Build.
+// Bind replaces it with the value bound to Name.
+type NamedParam struct {
+	Name string
+}
+
+// ParamExpr is a named parameter whose value is bound after the query is built
+type ParamExpr struct {
+	Name string
+}
+
+// Param returns a named parameter, e.g. B(OpGt, C("ID"), Param("minID"))
+func Param(name string) Expr { return &ParamExpr{Name: name} }
+
+func (p *ParamExpr) ToSQL() (string, []any) {
+	if !identifierPattern.MatchString(p.Name) {
+		panic("invalid parameter name: " + p.Name)
+	}
+	return "?", []any{NamedParam{Name: p.Name}}
+}
+
+// bindNamed rewrites the @name and :name parameters of sql into "?" placeholders and
+// returns the args for every placeholder in order, positional args filling the "?" ones
+func bindNamed(sql string, args []any) (string, []any) {
+	var sb strings.Builder
+	var bound []any
+	next := 0
+	var quote byte
+	for i := 0; i < len(sql); i++ {
+		ch := sql[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '?':
+			if next < len(args) {
+				bound = append(bound, args[next])
+			}
+			next++
+		case (ch == '@' || ch == ':') && isParamStart(sql, i):
+			j := i + 1
+			for j < len(sql) && (sql[j] == '_' || isLetter(sql[j]) || isDigit(sql[j])) {
+				j++
+			}
+			bound = append(bound, NamedParam{Name: sql[i+1 : j]})
+			sb.WriteByte('?')
+			i = j - 1
+			continue
+		}
+		sb.WriteByte(ch)
+	}
+	// Keep any surplus so the placeholder count check reports the mismatch
+	if next < len(args) {
+		bound = append(bound, args[next:]...)
+	}
+	return sb.String(), bound
+}
+
+// isParamStart reports whether the '@' or ':' at i starts a named parameter.
+// "::" casts, "@@"/"@>" operators and references such as {{col:Name}} are not parameters.
+func isParamStart(sql string, i int) bool {
+	if i+1 >= len(sql) || (sql[i+1] != '_' && !isLetter(sql[i+1])) {
+		return false
+	}
+	if i > 0 {
+		prev := sql[i-1]
+		if prev == ':' || prev == '@' || prev == '_' || isLetter(prev) || isDigit(prev) {
+			return false
+		}
+	}
+	return true
+}
+
+// Bind returns a copy of args in which every NamedParam is replaced by its value in params,
+// a map[string]any or a struct (or pointer to one) whose fields are matched by Go name
+// (case-insensitively, so @userID binds UserID) or db tag.
+// The same built query can be bound many times with different params.
+func Bind(args []any, params any) ([]any, error) {
+	lookup, err := paramLookup(params)
+	if err != nil {
+		return nil, err
+	}
+
+	bound := make([]any, len(args))
+	for i, arg := range args {
+		named, ok := arg.(NamedParam)
+		if !ok {
+			bound[i] = arg
+			continue
+		}
+		v, ok := lookup(named.Name)
+		if !ok {
+			return nil, fmt.Errorf("no value bound for parameter '%s'", named.Name)
+		}
+		bound[i] = v
+	}
+	return bound, nil
+}
+
+// paramLookup returns a function reading named values from a map or struct
+func paramLookup(params any) (func(string) (any, bool), error) {
+	if m, ok := params.(map[string]any); ok {
+		return func(name string) (any, bool) {
+			v, ok := m[name]
+			return v, ok
+		}, nil
+	}
+
+	v := reflect.ValueOf(params)
+	if v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("parameter map must have string keys, got %s", v.Type())
+		}
+		return func(name string) (any, bool) {
+			value := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+			if !value.IsValid() {
+				return nil, false
+			}
+			return value.Interface(), true
+		}, nil
+	case reflect.Struct:
+		t := v.Type()
+		return func(name string) (any, bool) {
+			for i := 0; i < t.NumField(); i++ {
+				field := t.Field(i)
+				if !field.IsExported() {
+					continue
+				}
+				if strings.EqualFold(field.Name, name) || strings.Split(field.Tag.Get("db"), ",")[0] == name {
+					return v.Field(i).Interface(), true
+				}
+			}
+			return nil, false
+		}, nil
+	default:
+		return nil, fmt.Errorf("parameters must be a map or a struct, got %T", params)
+	}
+}
+
+// BuildWith builds the query and binds its named parameters from params (see Bind)
+func (b *SelectBuilder) BuildWith(params any) (string, []any, error) {
+	query, args := b.Build()
+	bound, err := Bind(args, params)
+	if err != nil {
+		return "", nil, err
+	}
+	return query, bound, nil
+}
+
+// BuildWith builds the query and binds its named parameters from params (see Bind)
+func (q *RawQuery) BuildWith(params any) (string, []any, error) {
+	query, args := q.Build()
+	bound, err := Bind(args, params)
+	if err != nil {
+		return "", nil, err
+	}
+	return query, bound, nil
+}
@@ -0,0 +1,145 @@
+package querybuilder
+
+import (
+	"encoding/json"
+	"little-orm/internal/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParam_SelectBuilder(t *testing.T) {
+	setupTestRegistry()
+
+	builder := NewSelectBuilder(model.User{}).
+		Select("ID").
+		Where(And(B(OpGt, C("ID"), Param("minID")), Eq("Name", "john"), B(OpLike, C("Email"), Param("domain")))).
+		Dialect(Postgres)
+
+	query, args := builder.Build()
+	expected := "SELECT id FROM users WHERE id > $1 AND name = $2 AND email LIKE $3"
+	if query != expected {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expected, query)
+	}
+	if args[0] != (NamedParam{Name: "minID"}) || args[2] != (NamedParam{Name: "domain"}) {
+		t.Errorf("Expected named params in args, got %v", args)
+	}
+
+	// The same built query can be bound many times
+	for _, minID := range []int{1, 100} {
+		bound, err := Bind(args, map[string]any{"minID": minID, "domain": "%@x.com"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(bound) != 3 || bound[0] != minID || bound[1] != "john" || bound[2] != "%@x.com" {
+			t.Errorf("Expected args [%d john %%@x.com], got %v", minID, bound)
+		}
+	}
+	if args[0] != (NamedParam{Name: "minID"}) {
+		t.Errorf("Expected Bind not to modify args, got %v", args)
+	}
+}
+
+func TestBind_Struct(t *testing.T) {
+	type filter struct {
+		MinID  int
+		Domain string `db:"domain"`
+		hidden string
+	}
+	args := []any{NamedParam{Name: "MinID"}, 5, NamedParam{Name: "domain"}}
+
+	bound, err := Bind(args, &filter{MinID: 3, Domain: "x"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if bound[0] != 3 || bound[1] != 5 || bound[2] != "x" {
+		t.Errorf("Expected args [3 5 x], got %v", bound)
+	}
+
+	if _, err := Bind([]any{NamedParam{Name: "hidden"}}, filter{}); err == nil {
+		t.Error("Expected error for unexported field")
+	}
+	if _, err := Bind(args, map[string]any{"MinID": 1}); err == nil || !strings.Contains(err.Error(), "domain") {
+		t.Errorf("Expected missing parameter error, got %v", err)
+	}
+	if _, err := Bind(args, 42); err == nil {
+		t.Error("Expected error for unsupported params type")
+	}
+	if bound, err := Bind(args[:2], map[string]string{"MinID": "a"}); err != nil || bound[0] != "a" {
+		t.Errorf("Expected typed map to bind, got %v, %v", bound, err)
+	}
+}
+
+func TestRaw_NamedParams(t *testing.T) {
+	setupTestRegistry()
+
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	query, args, err := NewSelectBuilder(model.User{}).
+		Select("ID").
+		Where(Raw("{{col:ID}} > @minID AND {{col:Name}} = ? AND {{col:Email}}::text <> ':skip' AND created_at > :since", "john")).
+		Dialect(Postgres).
+		BuildWith(map[string]any{"minID": 10, "since": since})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "SELECT id FROM users WHERE id > $1 AND name = $2 AND email::text <> ':skip' AND created_at > $3"
+	if query != expected {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expected, query)
+	}
+	if len(args) != 3 || args[0] != 10 || args[1] != "john" || args[2] != since {
+		t.Errorf("Expected args [10 john %v], got %v", since, args)
+	}
+}
+
+func TestRawQuery_BuildWith(t *testing.T) {
+	setupTestRegistry()
+
+	q := NewRawQuery(model.User{}, "SELECT {{col:ID}} FROM {{table}} WHERE {{col:ID}} = @id OR {{col:ID}} = @id").Dialect(Postgres)
+	query, args, err := q.BuildWith(struct{ ID int }{7})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if query != "SELECT id FROM users WHERE id = $1 OR id = $2" {
+		t.Errorf("Unexpected query %s", query)
+	}
+	if len(args) != 2 || args[0] != 7 || args[1] != 7 {
+		t.Errorf("Expected args [7 7], got %v", args)
+	}
+
+	if _, _, err := q.BuildWith(map[string]any{}); err == nil {
+		t.Error("Expected error for unbound parameter")
+	}
+	if debug := q.DebugSQL(); !strings.HasSuffix(debug, "WHERE id = :id OR id = :id") {
+		t.Errorf("Expected named params in debug output, got %s", debug)
+	}
+}
+
+func TestParam_ParseAndJSON(t *testing.T) {
+	setupTestRegistry()
+
+	expr, err := ParsePredicate(model.User{}, "ID > :minID AND Name = @name")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, err := json.Marshal(expr)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	decoded, err := DecodeFilter(model.User{}, data, DecodeOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, args, err := NewSelectBuilder(model.User{}).Where(decoded).BuildWith(map[string]any{"minID": 1, "name": "a"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(args) != 2 || args[0] != 1 || args[1] != "a" {
+		t.Errorf("Expected args [1 a], got %v", args)
+	}
+
+	if _, err := DecodeExpr([]byte(`{"type":"param","name":"a; drop"}`), DecodeOptions{}); err == nil {
+		t.Error("Expected error for invalid parameter name")
+	}
+}
@@ -0,0 +1,479 @@
+package querybuilder
+
+import (
+	"fmt"
+	"little-orm/internal/database/registry"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseError reports an invalid filter expression and where it was found
+type ParseError struct {
+	Offset int // byte offset in the input
+	Line   int // 1-based line
+	Column int // 1-based column, counted in characters
+	Msg    string
+	Err    error // underlying validation error, if any
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("parse error at line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// ParseExpr parses a human-written predicate such as
+//
+//	Name LIKE 'J%' AND (ID > 10 OR Email IS NULL)
+//
+// into an expression tree. Identifiers are kept as written (Go field names) and every
+// literal becomes a LiteralExpr parameter. Supported syntax:
+//
+//	a = b, a != b, a <> b, a < b, a <= b, a > b, a >= b
+//	a [NOT] LIKE 'pattern', a [NOT] IN (v1, v2), a [NOT] BETWEEN lo AND hi, a IS [NOT] NULL
+//	NOT p, p AND q, p OR q, (p)
+//
+// Literals are 'strings' (quotes doubled to escape), integers, decimals, TRUE and FALSE.
+// Named parameters (@name or :name) become ParamExpr, bound after building with Bind.
+// Keywords are case-insensitive.
+func ParseExpr(src string) (Expr, error) {
+	return parseExpr(src, nil)
+}
+
+// ParsePredicate parses src like ParseExpr and checks every field against the columns of
+// model. The returned expression keeps Go field names and can be passed to SelectBuilder.Where.
+func ParsePredicate(model any, src string) (Expr, error) {
+	return ParsePredicateWith(registry.GetDBRegistry(), model, src)
+}
+
+// ParsePredicateWith is ParsePredicate for a model registered in reg.
+// It returns an error when the model is not registered.
+func ParsePredicateWith(reg *registry.DBRegistry, model any, src string) (Expr, error) {
+	tableMeta, err := reg.LookupTableMeta(model)
+	if err != nil {
+		return nil, err
+	}
+	return parseExpr(src, &ExprValidator{tableMeta: tableMeta})
+}
+
+func parseExpr(src string, validator *ExprValidator) (Expr, error) {
+	p := &exprParser{lexer: exprLexer{src: src}, validator: validator}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	expr, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf(p.tok.pos, "unexpected %s", p.tok)
+	}
+	return expr, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokKeyword
+	tokString
+	tokNumber
+	tokParam
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type token struct {
+	kind tokenKind
+	text string // keywords are upper-cased, strings unquoted
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of input"
+	case tokString:
+		return fmt.Sprintf("string '%s'", t.text)
+	case tokNumber:
+		return "number " + t.text
+	case tokIdent:
+		return fmt.Sprintf("identifier '%s'", t.text)
+	case tokParam:
+		return fmt.Sprintf("parameter '%s'", t.text)
+	default:
+		return fmt.Sprintf("'%s'", t.text)
+	}
+}
+
+var parseKeywords = map[string]bool{
+	"AND": true, "OR": true, "NOT": true, "LIKE": true, "IN": true,
+	"BETWEEN": true, "IS": true, "NULL": true, "TRUE": true, "FALSE": true,
+}
+
+type exprLexer struct {
+	src string
+	pos int
+}
+
+func (l *exprLexer) scan() (token, *ParseError) {
+	for l.pos < len(l.src) && strings.IndexByte(" \t\r\n", l.src[l.pos]) >= 0 {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, pos: start}, nil
+	}
+
+	ch := l.src[l.pos]
+	switch {
+	case ch == '(':
+		l.pos++
+		return token{kind: tokLParen, text: "(", pos: start}, nil
+	case ch == ')':
+		l.pos++
+		return token{kind: tokRParen, text: ")", pos: start}, nil
+	case ch == ',':
+		l.pos++
+		return token{kind: tokComma, text: ",", pos: start}, nil
+	case ch == '\'':
+		return l.scanString()
+	case (ch == '@' || ch == ':') && isParamStart(l.src, l.pos):
+		l.pos++
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || isLetter(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokParam, text: l.src[start+1 : l.pos], pos: start}, nil
+	case isDigit(ch) || (ch == '-' || ch == '.') && l.pos+1 < len(l.src) && isDigit(l.src[l.pos+1]):
+		l.pos++
+		for l.pos < len(l.src) && (isDigit(l.src[l.pos]) || l.src[l.pos] == '.') {
+			l.pos++
+		}
+		return token{kind: tokNumber, text: l.src[start:l.pos], pos: start}, nil
+	case ch == '_' || isLetter(ch):
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || isLetter(l.src[l.pos]) || isDigit(l.src[l.pos]) ||
+			// fields of inline structs are qualified, e.g. Address.City
+			l.src[l.pos] == '.' && l.pos+1 < len(l.src) && isLetter(l.src[l.pos+1])) {
+			l.pos++
+		}
+		word := l.src[start:l.pos]
+		if upper := strings.ToUpper(word); parseKeywords[upper] {
+			return token{kind: tokKeyword, text: upper, pos: start}, nil
+		}
+		return token{kind: tokIdent, text: word, pos: start}, nil
+	}
+
+	for _, op := range []string{"<=", ">=", "<>", "!=", "=", "<", ">"} {
+		if strings.HasPrefix(l.src[l.pos:], op) {
+			l.pos += len(op)
+			return token{kind: tokOp, text: op, pos: start}, nil
+		}
+	}
+	r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+	return token{}, newParseError(l.src, start, fmt.Sprintf("unexpected character %q", r), nil)
+}
+
+func (l *exprLexer) scanString() (token, *ParseError) {
+	start := l.pos
+	l.pos++
+	var sb strings.Builder
+	for l.pos < len(l.src) {
+		ch := l.src[l.pos]
+		l.pos++
+		if ch != '\'' {
+			sb.WriteByte(ch)
+			continue
+		}
+		// '' is an escaped quote
+		if l.pos < len(l.src) && l.src[l.pos] == '\'' {
+			sb.WriteByte('\'')
+			l.pos++
+			continue
+		}
+		return token{kind: tokString, text: sb.String(), pos: start}, nil
+	}
+	return token{}, newParseError(l.src, start, "unterminated string", nil)
+}
+
+func isDigit(ch byte) bool  { return ch >= '0' && ch <= '9' }
+func isLetter(ch byte) bool { return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' }
+
+type exprParser struct {
+	lexer     exprLexer
+	tok       token
+	validator *ExprValidator
+}
+
+func newParseError(src string, offset int, msg string, err error) *ParseError {
+	line, col := 1, 1
+	for _, r := range src[:offset] {
+		if r == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return &ParseError{Offset: offset, Line: line, Column: col, Msg: msg, Err: err}
+}
+
+func (p *exprParser) errorf(offset int, format string, args ...any) error {
+	return newParseError(p.lexer.src, offset, fmt.Sprintf(format, args...), nil)
+}
+
+func (p *exprParser) next() error {
+	tok, err := p.lexer.scan()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+// keyword consumes the current token if it is the keyword kw
+func (p *exprParser) keyword(kw string) (bool, error) {
+	if p.tok.kind != tokKeyword || p.tok.text != kw {
+		return false, nil
+	}
+	return true, p.next()
+}
+
+func (p *exprParser) expect(kind tokenKind, what string) error {
+	if p.tok.kind != kind {
+		return p.errorf(p.tok.pos, "expected %s, found %s", what, p.tok)
+	}
+	return p.next()
+}
+
+func (p *exprParser) parseOr(depth int) (Expr, error) {
+	terms := []Expr{}
+	for {
+		term, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+		if ok, err := p.keyword("OR"); err != nil || !ok {
+			return Or(terms...), err
+		}
+	}
+}
+
+func (p *exprParser) parseAnd(depth int) (Expr, error) {
+	terms := []Expr{}
+	for {
+		term, err := p.parseNot(depth)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+		if ok, err := p.keyword("AND"); err != nil || !ok {
+			return And(terms...), err
+		}
+	}
+}
+
+func (p *exprParser) parseNot(depth int) (Expr, error) {
+	if depth > DefaultMaxDepth {
+		return nil, p.errorf(p.tok.pos, "expression nested deeper than %d levels", DefaultMaxDepth)
+	}
+	if ok, err := p.keyword("NOT"); err != nil {
+		return nil, err
+	} else if ok {
+		operand, err := p.parseNot(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return Not(operand), nil
+	}
+	if p.tok.kind == tokLParen {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		expr, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return expr, p.expect(tokRParen, "')'")
+	}
+	return p.parsePredicate()
+}
+
+var parseComparisonOps = map[string]Op{
+	"=": OpEq, "!=": OpNEq, "<>": OpNEq, "<": OpLt, "<=": OpLte, ">": OpGt, ">=": OpGte,
+}
+
+func (p *exprParser) parsePredicate() (Expr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind == tokOp {
+		op := parseComparisonOps[p.tok.text]
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return B(op, left, right), nil
+	}
+
+	if ok, err := p.keyword("IS"); err != nil {
+		return nil, err
+	} else if ok {
+		op := OpIsNull
+		if ok, err := p.keyword("NOT"); err != nil {
+			return nil, err
+		} else if ok {
+			op = OpIsNNull
+		}
+		if ok, err := p.keyword("NULL"); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, p.errorf(p.tok.pos, "expected NULL, found %s", p.tok)
+		}
+		return U(op, left), nil
+	}
+
+	negated, err := p.keyword("NOT")
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokKeyword {
+		return nil, p.errorf(p.tok.pos, "expected comparison operator, found %s", p.tok)
+	}
+
+	var expr Expr
+	switch p.tok.text {
+	case "LIKE":
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		pattern, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		expr = B(OpLike, left, pattern)
+	case "IN":
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		if negated {
+			return B(OpNIn, left, L(values)), nil
+		}
+		return B(OpIn, left, L(values)), nil
+	case "BETWEEN":
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		low, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("AND"); err != nil {
+			return nil, err
+		}
+		high, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		expr = T(left, low, high)
+	default:
+		return nil, p.errorf(p.tok.pos, "expected comparison operator, found %s", p.tok)
+	}
+	if negated {
+		return Not(expr), nil
+	}
+	return expr, nil
+}
+
+func (p *exprParser) expectKeyword(kw string) error {
+	ok, err := p.keyword(kw)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return p.errorf(p.tok.pos, "expected %s, found %s", kw, p.tok)
+	}
+	return nil
+}
+
+// parseList parses a parenthesized, comma separated list of literals
+func (p *exprParser) parseList() ([]any, error) {
+	if err := p.expect(tokLParen, "'('"); err != nil {
+		return nil, err
+	}
+	var values []any
+	for {
+		pos := p.tok.pos
+		operand, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		lit, ok := operand.(*LiteralExpr)
+		if !ok {
+			return nil, p.errorf(pos, "IN list may only contain literal values")
+		}
+		values = append(values, lit.Value)
+		if p.tok.kind != tokComma {
+			break
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+	return values, p.expect(tokRParen, "')'")
+}
+
+// parseOperand parses a field name, a literal or a named parameter
+func (p *exprParser) parseOperand() (Expr, error) {
+	tok := p.tok
+	var expr Expr
+	switch {
+	case tok.kind == tokIdent:
+		if p.validator != nil {
+			if _, err := p.validator.Resolve(C(tok.text)); err != nil {
+				return nil, newParseError(p.lexer.src, tok.pos, fmt.Sprintf("unknown field '%s'", tok.text), err)
+			}
+		}
+		expr = C(tok.text)
+	case tok.kind == tokString:
+		expr = L(tok.text)
+	case tok.kind == tokParam:
+		expr = Param(tok.text)
+	case tok.kind == tokNumber:
+		v, err := parseNumber(tok.text)
+		if err != nil {
+			return nil, p.errorf(tok.pos, "invalid number %s", tok.text)
+		}
+		expr = L(v)
+	case tok.kind == tokKeyword && (tok.text == "TRUE" || tok.text == "FALSE"):
+		expr = L(tok.text == "TRUE")
+	case tok.kind == tokKeyword && tok.text == "NULL":
+		return nil, p.errorf(tok.pos, "NULL is only allowed in IS NULL / IS NOT NULL")
+	default:
+		return nil, p.errorf(tok.pos, "expected field or value, found %s", tok)
+	}
+	return expr, p.next()
+}
+
+// parseNumber returns an int for integer literals and a float64 otherwise
+func parseNumber(text string) (any, error) {
+	if !strings.Contains(text, ".") {
+		if v, err := strconv.Atoi(text); err == nil {
+			return v, nil
+		}
+	}
+	return strconv.ParseFloat(text, 64)
+}
@@ -0,0 +1,169 @@
+package querybuilder
+
+import (
+	"errors"
+	"little-orm/internal/model"
+	"strings"
+	"testing"
+)
+
+func TestParseExpr(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+		args     int
+	}{
+		{"Name LIKE 'J%' AND (ID > 10 OR Email IS NULL)", "Name LIKE ? AND (ID > ? OR Email IS NULL)", 2},
+		{"ID = 1 OR ID = 2 AND Name != 'x'", "ID = ? OR ID = ? AND Name != ?", 3},
+		{"not ID in (1, 2, 3)", "NOT (ID IN (?, ?, ?))", 3},
+		{"ID NOT IN (1)", "ID NOT IN (?)", 1},
+		{"ID between 1 and 10", "ID BETWEEN ? AND ?", 2},
+		{"ID NOT BETWEEN -1 AND 1.5", "NOT (ID BETWEEN ? AND ?)", 2},
+		{"Email IS NOT NULL", "Email IS NOT NULL", 0},
+		{"Name NOT LIKE 'a%'", "NOT (Name LIKE ?)", 1},
+		{"ID <> 3", "ID != ?", 1},
+		{"Address.City = 'x'", "Address.City = ?", 1},
+	}
+
+	for _, tt := range tests {
+		expr, err := ParseExpr(tt.src)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.src, err)
+			continue
+		}
+		sql, args := expr.ToSQL()
+		if sql != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.src, tt.expected, sql)
+		}
+		if len(args) != tt.args {
+			t.Errorf("%s: expected %d args, got %d", tt.src, tt.args, len(args))
+		}
+	}
+}
+
+func TestParseExpr_Literals(t *testing.T) {
+	expr, err := ParseExpr("Name = 'O''Brien' AND ID = 42 AND ID < 1.5 AND Name = TRUE")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_, args := expr.ToSQL()
+	expected := []any{"O'Brien", 42, 1.5, true}
+	if len(args) != len(expected) {
+		t.Fatalf("Expected args %v, got %v", expected, args)
+	}
+	for i := range expected {
+		if args[i] != expected[i] {
+			t.Errorf("Expected arg %d to be %#v, got %#v", i, expected[i], args[i])
+		}
+	}
+}
+
+func TestParseExpr_NeverInterpolates(t *testing.T) {
+	expr, err := ParseExpr("Name = '1; DROP TABLE users; --'")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	sql, args := expr.ToSQL()
+	if sql != "Name = ?" {
+		t.Errorf("Expected Name = ?, got %s", sql)
+	}
+	if len(args) != 1 || args[0] != "1; DROP TABLE users; --" {
+		t.Errorf("Expected the string as a parameter, got %v", args)
+	}
+}
+
+func TestParseExpr_ErrorPositions(t *testing.T) {
+	tests := []struct {
+		src    string
+		line   int
+		column int
+	}{
+		{"ID > ", 1, 6},
+		{"ID > 1 AND (Name = 'x'", 1, 23},
+		{"ID > 1\nAND Name ~ 'x'", 2, 10},
+		{"Name = 'abc", 1, 8},
+		{"ID = NULL", 1, 6},
+		{"ID IN (1, Name)", 1, 11},
+		{"ID 5", 1, 4},
+		{"ID = 1 ID = 2", 1, 8},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseExpr(tt.src)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("%q: expected ParseError, got %v", tt.src, err)
+			continue
+		}
+		if parseErr.Line != tt.line || parseErr.Column != tt.column {
+			t.Errorf("%q: expected error at %d:%d, got %d:%d (%v)", tt.src, tt.line, tt.column, parseErr.Line, parseErr.Column, err)
+		}
+	}
+}
+
+func TestParseExpr_DepthLimit(t *testing.T) {
+	src := ""
+	for i := 0; i < DefaultMaxDepth+2; i++ {
+		src += "("
+	}
+	if _, err := ParseExpr(src + "ID = 1"); err == nil {
+		t.Error("Expected error for deeply nested expression")
+	}
+}
+
+func TestParsePredicate(t *testing.T) {
+	setupTestRegistry()
+
+	expr, err := ParsePredicate(model.User{}, "Name LIKE 'J%' AND (ID > 10 OR Email IS NULL)")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := NewSelectBuilder(model.User{}).Select("ID").Where(expr).Build()
+	expected := "SELECT id FROM users WHERE name LIKE ? AND (id > ? OR email IS NULL)"
+	if query != expected {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expected, query)
+	}
+	if len(args) != 2 || args[0] != "J%" || args[1] != 10 {
+		t.Errorf("Expected args [J%% 10], got %v", args)
+	}
+}
+
+func TestParsePredicateWith(t *testing.T) {
+	t.Parallel()
+
+	reg := newLibraryRegistry()
+	expr, err := ParsePredicateWith(reg, Author{}, "Name LIKE 'a%' AND ID > 1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, _ := NewSelectBuilderWith(reg, Author{}).Select("ID").Where(expr).Build()
+	expected := "SELECT id FROM authors WHERE name LIKE ? AND id > ?"
+	if query != expected {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expected, query)
+	}
+
+	if _, err := ParsePredicateWith(reg, Author{}, "Bio = 'x'"); err == nil {
+		t.Error("Expected error for a field of another model")
+	}
+	if _, err := ParsePredicateWith(reg, model.User{}, "ID > 1"); err == nil || !strings.Contains(err.Error(), "is not registered") {
+		t.Errorf("Expected an unregistered model error, got %v", err)
+	}
+}
+
+func TestParsePredicate_UnknownField(t *testing.T) {
+	setupTestRegistry()
+
+	_, err := ParsePredicate(model.User{}, "ID > 1 AND Emial = 'x'")
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected ParseError, got %v", err)
+	}
+	if parseErr.Column != 12 {
+		t.Errorf("Expected error at column 12, got %d", parseErr.Column)
+	}
+	if parseErr.Err == nil {
+		t.Error("Expected the validation error to be wrapped")
+	}
+}
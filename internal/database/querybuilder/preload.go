@@ -0,0 +1,234 @@
+package querybuilder
+
+import (
+	"context"
+	"fmt"
+	"little-orm/internal/database/registry"
+	"math"
+	"reflect"
+)
+
+// preloadNode is a relation to preload; children are the relations preloaded on its models
+type preloadNode struct {
+	// model is the struct type loaded at this node
+	model    reflect.Type
+	relation registry.Relation
+	// builder selects the related models, before filtering on the keys of the parents
+	builder *SelectBuilder
+	// parentKey and targetKey are the Go fields matching parents to related models,
+	// located by parentIndex and targetIndex (for many2many, through the join table)
+	parentKey, targetKey     string
+	parentIndex, targetIndex []int
+	children                 map[string]*preloadNode
+	order                    []string
+}
+
+// child returns the node preloading the relation name of n's model, creating it if needed
+func (n *preloadNode) child(reg *registry.DBRegistry, name string) *preloadNode {
+	if c, ok := n.children[name]; ok {
+		return c
+	}
+	parent := reflect.New(n.model).Interface()
+	rel, ok := reg.GetTableMeta(parent).Relation(name)
+	if !ok {
+		panic(fmt.Sprintf("Relation %s is not declared on %s", name, n.model.Name()))
+	}
+	target := reflect.New(rel.Target).Interface()
+	if _, err := reg.LookupTableMeta(target); err != nil {
+		panic(fmt.Sprintf("Relation %s of %s cannot be preloaded, its target isn't registered (see DBRegistry.ValidateRelations): %v",
+			name, n.model.Name(), err))
+	}
+	builder := NewSelectBuilderWith(reg, target)
+	// Re-read the relation, resolved now that the target is registered
+	parentMeta := reg.GetTableMeta(parent)
+	rel, _ = parentMeta.Relation(name)
+
+	c := &preloadNode{
+		model:     rel.Target,
+		relation:  rel,
+		builder:   builder,
+		parentKey: rel.References,
+		targetKey: rel.ForeignKey,
+		children:  map[string]*preloadNode{},
+	}
+	switch rel.Kind {
+	case registry.BelongsTo:
+		c.parentKey, c.targetKey = rel.ForeignKey, rel.References
+	case registry.ManyToMany:
+		c.targetKey = rel.TargetKey
+	}
+	parentCol, _ := parentMeta.Column(c.parentKey)
+	targetCol, _ := builder.tableMeta.Column(c.targetKey)
+	c.parentIndex, c.targetIndex = parentCol.Index, targetCol.Index
+
+	n.children[name] = c
+	n.order = append(n.order, name)
+	return c
+}
+
+// load preloads the children of n onto models, pointers to n's model
+func (n *preloadNode) load(ctx context.Context, db Querier, d Dialect, models []reflect.Value) error {
+	for _, name := range n.order {
+		if err := n.children[name].loadRelation(ctx, db, d, models); err != nil {
+			return fmt.Errorf("preload %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// loadRelation loads the related models of parents with a single query, preloads their own
+// relations, then sets them on the parents
+func (n *preloadNode) loadRelation(ctx context.Context, db Querier, d Dialect, parents []reflect.Value) error {
+	if len(parents) == 0 {
+		return nil
+	}
+
+	keys := n.distinctKeys(parents)
+	if len(keys) == 0 {
+		n.assign(parents, nil, nil)
+		return nil
+	}
+
+	// For many2many, the join table maps parent keys to target keys
+	var links map[any][]any
+	targetKeys := keys
+	if n.relation.Kind == registry.ManyToMany {
+		var err error
+		if links, targetKeys, err = n.loadLinks(ctx, db, d, keys); err != nil {
+			return err
+		}
+		if len(targetKeys) == 0 {
+			n.assign(parents, links, nil)
+			return nil
+		}
+	}
+
+	query, args := n.builder.Clone().AndWhere(In(n.targetKey, targetKeys)).Build()
+	related, err := queryModels(ctx, db, d, query, args, n.model, n.builder.tableMeta)
+	if err != nil {
+		return err
+	}
+	if err := n.load(ctx, db, d, related); err != nil {
+		return err
+	}
+	n.assign(parents, links, related)
+	return nil
+}
+
+// loadLinks reads the rows of the many2many join table for the parent keys, returning the
+// target keys of each parent key and all target keys
+func (n *preloadNode) loadLinks(ctx context.Context, db Querier, d Dialect, keys []any) (map[any][]any, []any, error) {
+	rel := n.relation
+	query := fmt.Sprintf("SELECT %s, %s FROM %s WHERE %s IN ?", rel.JoinForeignKey, rel.JoinReferences, rel.JoinTable, rel.JoinForeignKey)
+	rows, err := run(ctx, db, d, query, []any{keys})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	links := make(map[any][]any)
+	seen := make(map[any]bool)
+	var targetKeys []any
+	for rows.Next() {
+		var parentKey, targetKey any
+		if err := rows.Scan(&parentKey, &targetKey); err != nil {
+			return nil, nil, err
+		}
+		pk, tk := normalizeKey(reflect.ValueOf(parentKey)), normalizeKey(reflect.ValueOf(targetKey))
+		links[pk] = append(links[pk], tk)
+		if !seen[tk] {
+			seen[tk] = true
+			targetKeys = append(targetKeys, tk)
+		}
+	}
+	return links, targetKeys, rows.Err()
+}
+
+// assign sets the related models on the relation field of every parent
+func (n *preloadNode) assign(parents []reflect.Value, links map[any][]any, related []reflect.Value) {
+	byKey := make(map[any][]reflect.Value)
+	for _, r := range related {
+		key := normalizeKey(fieldByIndex(r.Elem(), n.targetIndex))
+		byKey[key] = append(byKey[key], r)
+	}
+
+	for _, p := range parents {
+		key := normalizeKey(fieldByIndex(p.Elem(), n.parentIndex))
+		matches := byKey[key]
+		if n.relation.Kind == registry.ManyToMany {
+			matches = nil
+			for _, targetKey := range links[key] {
+				matches = append(matches, byKey[targetKey]...)
+			}
+		}
+
+		field := p.Elem().FieldByIndex(n.relation.Index)
+		switch n.relation.Kind {
+		case registry.HasMany, registry.ManyToMany:
+			list := reflect.MakeSlice(field.Type(), 0, len(matches))
+			for _, m := range matches {
+				list = reflect.Append(list, relatedValue(m, field.Type().Elem()))
+			}
+			field.Set(list)
+		default:
+			if len(matches) > 0 {
+				field.Set(relatedValue(matches[0], field.Type()))
+			} else {
+				field.Set(reflect.Zero(field.Type()))
+			}
+		}
+	}
+}
+
+// relatedValue converts m, a pointer to a related model, to the type t of a relation field
+// or slice element: the pointer itself or a copy of the model
+func relatedValue(m reflect.Value, t reflect.Type) reflect.Value {
+	if t.Kind() == reflect.Pointer {
+		return m
+	}
+	return m.Elem()
+}
+
+// distinctKeys returns the distinct non-nil parent keys of parents, in order
+func (n *preloadNode) distinctKeys(parents []reflect.Value) []any {
+	seen := make(map[any]bool)
+	var keys []any
+	for _, p := range parents {
+		key := normalizeKey(fieldByIndex(p.Elem(), n.parentIndex))
+		if key == nil || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// normalizeKey returns a comparable map key for a key value, so that compatible key types
+// (e.g. int and int64 columns, or driver values) match; nil pointers give nil
+func normalizeKey(v reflect.Value) any {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if u := v.Uint(); u <= math.MaxInt64 {
+			return int64(u)
+		}
+		return v.Uint()
+	case reflect.String:
+		return v.String()
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes())
+		}
+	case reflect.Invalid:
+		return nil
+	}
+	return v.Interface()
+}
@@ -0,0 +1,203 @@
+package querybuilder
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"little-orm/internal/database/registry"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFind_Preload(t *testing.T) {
+	t.Parallel()
+
+	db := newFakeDB()
+	db.on("SELECT id, name FROM authors",
+		[]string{"id", "name"},
+		[]driver.Value{int64(1), "ann"},
+		[]driver.Value{int64(2), "bob"},
+	)
+	db.on("SELECT id, author_id, title FROM posts WHERE title LIKE ? AND author_id IN (?, ?) ORDER BY id ASC",
+		[]string{"id", "author_id", "title"},
+		[]driver.Value{int64(10), int64(1), "go tips"},
+		[]driver.Value{int64(11), int64(1), "go idioms"},
+		[]driver.Value{int64(12), int64(2), "go testing"},
+	)
+	db.on("SELECT id, post_id, body FROM comments WHERE post_id IN (?, ?, ?)",
+		[]string{"id", "post_id", "body"},
+		[]driver.Value{int64(100), int64(10), "nice"},
+		[]driver.Value{int64(101), int64(12), "thanks"},
+	)
+	db.on("SELECT post_id, tag_id FROM post_tags WHERE post_id IN (?, ?, ?)",
+		[]string{"post_id", "tag_id"},
+		[]driver.Value{int64(10), int64(1)},
+		[]driver.Value{int64(10), int64(2)},
+		[]driver.Value{int64(12), int64(1)},
+	)
+	db.on("SELECT id, name FROM tags WHERE id IN (?, ?)",
+		[]string{"id", "name"},
+		[]driver.Value{int64(1), "go"},
+		[]driver.Value{int64(2), "sql"},
+	)
+	db.on("SELECT id, author_id, bio FROM profiles WHERE author_id IN (?, ?)",
+		[]string{"id", "author_id", "bio"},
+		[]driver.Value{int64(5), int64(2), "gopher"},
+	)
+
+	authors, err := FindWith[Author](newLibraryRegistry()).
+		Preload("Posts", Where(Like("Title", "go%")), OrderBy("id", "ASC")).
+		Preload("Posts.Comments").
+		Preload("Posts.Tags").
+		Preload("Profile").
+		All(context.Background(), sql.OpenDB(db))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []Author{
+		{ID: 1, Name: "ann", Posts: []Post{
+			{ID: 10, AuthorID: 1, Title: "go tips",
+				Comments: []*Comment{{ID: 100, PostID: 10, Body: "nice"}},
+				Tags:     []Tag{{ID: 1, Name: "go"}, {ID: 2, Name: "sql"}}},
+			{ID: 11, AuthorID: 1, Title: "go idioms", Comments: []*Comment{}, Tags: []Tag{}},
+		}},
+		{ID: 2, Name: "bob", Posts: []Post{
+			{ID: 12, AuthorID: 2, Title: "go testing",
+				Comments: []*Comment{{ID: 101, PostID: 12, Body: "thanks"}},
+				Tags:     []Tag{{ID: 1, Name: "go"}}},
+		}, Profile: &Profile{ID: 5, AuthorID: 2, Bio: "gopher"}},
+	}
+	if !reflect.DeepEqual(authors, expected) {
+		t.Errorf("Expected:\n%+v\nGot:\n%+v", expected, authors)
+	}
+
+	// One query for the authors, one per relation and one for the join table
+	if len(db.queries) != 6 {
+		t.Errorf("Expected 6 queries, got %d:\n%s", len(db.queries), strings.Join(db.queries, "\n"))
+	}
+	if !reflect.DeepEqual(db.args[1], []any{"go%", int64(1), int64(2)}) {
+		t.Errorf("Expected posts args [go%% 1 2], got %v", db.args[1])
+	}
+}
+
+func TestFind_Preload_BelongsTo(t *testing.T) {
+	t.Parallel()
+
+	db := newFakeDB()
+	db.on("SELECT id, author_id, title FROM posts WHERE id > $1",
+		[]string{"id", "author_id", "title"},
+		[]driver.Value{int64(10), int64(1), "a"},
+		[]driver.Value{int64(11), int64(1), "b"},
+		[]driver.Value{int64(12), int64(3), "c"},
+	)
+	db.on("SELECT id, name FROM authors WHERE id IN ($1, $2)",
+		[]string{"id", "name"},
+		[]driver.Value{int64(1), "ann"},
+	)
+
+	posts, err := FindWith[Post](newLibraryRegistry()).
+		Dialect(Postgres).
+		Where(Gt("ID", 5)).
+		Preload("Author").
+		All(context.Background(), sql.OpenDB(db))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(posts) != 3 {
+		t.Fatalf("Expected 3 posts, got %d", len(posts))
+	}
+	if posts[0].Author == nil || posts[0].Author.Name != "ann" || posts[0].Author != posts[1].Author {
+		t.Errorf("Expected posts 10 and 11 to share author ann, got %+v and %+v", posts[0].Author, posts[1].Author)
+	}
+	if posts[2].Author != nil {
+		t.Errorf("Expected no author for a missing key, got %+v", posts[2].Author)
+	}
+}
+
+func TestFind_Preload_NoParents(t *testing.T) {
+	t.Parallel()
+
+	db := newFakeDB()
+	db.on("SELECT id, name FROM authors", []string{"id", "name"})
+
+	authors, err := FindWith[Author](newLibraryRegistry()).
+		Preload("Posts.Tags").
+		All(context.Background(), sql.OpenDB(db))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(authors) != 0 || len(db.queries) != 1 {
+		t.Errorf("Expected no authors and no preload query, got %v after %v", authors, db.queries)
+	}
+}
+
+func TestFind_Preload_Error(t *testing.T) {
+	t.Parallel()
+
+	db := newFakeDB()
+	db.on("SELECT id, name FROM authors", []string{"id", "name"}, []driver.Value{int64(1), "ann"})
+
+	_, err := FindWith[Author](newLibraryRegistry()).
+		Preload("Posts").
+		All(context.Background(), sql.OpenDB(db))
+	if err == nil || !strings.HasPrefix(err.Error(), "preload Posts: ") {
+		t.Errorf("Expected a preload error, got %v", err)
+	}
+}
+
+func TestFind_Preload_Unknown(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"Comments", "Relation Comments is not declared on Author"},
+		{"Posts.Profile", "Relation Profile is not declared on Post"},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				r := recover()
+				if r == nil || !strings.Contains(r.(string), tt.expected) {
+					t.Errorf("%s: expected panic '%s', got %v", tt.path, tt.expected, r)
+				}
+			}()
+			FindWith[Author](newLibraryRegistry()).Preload(tt.path)
+		}()
+	}
+}
+
+func TestFind_Preload_UnregisteredTarget(t *testing.T) {
+	t.Parallel()
+
+	// Register doesn't report relations to models that are never registered
+	reg := registry.New()
+	for _, m := range []any{Author{}, Post{}, Comment{}, Tag{}} {
+		if err := reg.Register(m); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	defer func() {
+		r, _ := recover().(string)
+		if !strings.Contains(r, "Relation Profile of Author cannot be preloaded") || !strings.Contains(r, "Profile is not registered") {
+			t.Errorf("Expected a panic for the unregistered target, got %q", r)
+		}
+	}()
+	FindWith[Author](reg).Preload("Profile")
+}
+
+func TestFind_Preload_InvalidWhere(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic for a field of the parent used on the relation")
+		}
+	}()
+	FindWith[Author](newLibraryRegistry()).Preload("Posts", Where(Eq("Name", "x")))
+}
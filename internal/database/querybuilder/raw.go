@@ -0,0 +1,147 @@
+package querybuilder
+
+import (
+	"errors"
+	"fmt"
+	"little-orm/internal/database/registry"
+	"regexp"
+)
+
+// columnRefPattern matches {{col:Field}} references to Go fields inside raw SQL
+var columnRefPattern = regexp.MustCompile(`\{\{\s*col:([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// tableRefPattern matches the {{table}} reference to the model's table inside raw SQL
+var tableRefPattern = regexp.MustCompile(`\{\{\s*table\s*\}\}`)
+
+// RawExpr is a SQL fragment written by hand, for what the builder doesn't support yet.
+// Its "?" placeholders are bound to Args in order and rewritten for the dialect like any
+// other argument; {{col:Field}} references are replaced by the field's column on validation.
+// Args holds a NamedParam for each named parameter.
+type RawExpr struct {
+	SQL  string
+	Args []any
+}
+
+// Raw returns a raw SQL fragment, e.g. Raw("date_trunc('day', {{col:CreatedAt}}) = ?", t).
+// Named parameters (@name or :name) are bound later with Bind, e.g. Raw("{{col:CreatedAt}} > :since").
+// Values must always be passed as args or parameters, never concatenated into sql.
+func Raw(sql string, args ...any) Expr {
+	sql, args = bindNamed(sql, args)
+	return &RawExpr{SQL: sql, Args: args}
+}
+
+func (r *RawExpr) ToSQL() (string, []any) {
+	if columnRefPattern.MatchString(r.SQL) {
+		panic("raw SQL has unresolved column references: " + r.SQL)
+	}
+	if err := checkPlaceholders(r.SQL, len(r.Args)); err != nil {
+		panic(err.Error())
+	}
+	return r.SQL, r.Args
+}
+
+func (r *RawExpr) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("raw SQL expressions cannot be serialized")
+}
+
+// resolveRaw replaces the column references of sql with the columns of tableMeta
+func resolveRaw(sql string, tableMeta registry.TableMeta) (string, error) {
+	var err error
+	resolved := columnRefPattern.ReplaceAllStringFunc(sql, func(ref string) string {
+		field := columnRefPattern.FindStringSubmatch(ref)[1]
+		colMeta, ok := tableMeta.Columns[field]
+		if !ok {
+			if err == nil {
+				err = fmt.Errorf("column '%s' not found in table '%s'", field, tableMeta.TableName)
+			}
+			return ref
+		}
+		return colMeta.DBTag
+	})
+	return resolved, err
+}
+
+// countPlaceholders counts the "?" placeholders of sql outside quoted strings and identifiers
+func countPlaceholders(sql string) int {
+	n := 0
+	var quote byte
+	for i := 0; i < len(sql); i++ {
+		ch := sql[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '?':
+			n++
+		}
+	}
+	return n
+}
+
+func checkPlaceholders(sql string, args int) error {
+	if n := countPlaceholders(sql); n != args {
+		return fmt.Errorf("raw SQL has %d placeholders but %d args: %s", n, args, sql)
+	}
+	return nil
+}
+
+// RawQuery is a hand-written query implementing QueryBuilder
+type RawQuery struct {
+	sql     string
+	args    []any
+	dialect Dialect
+}
+
+// NewRawQuery creates a query from hand-written SQL with "?" placeholders bound to args
+// and named parameters (@name or :name) bound later with BuildWith. When model is not nil,
+// {{table}} and {{col:Field}} references are replaced by the model's table and columns;
+// unknown fields and placeholder/arg mismatches panic.
+func NewRawQuery(model any, sql string, args ...any) *RawQuery {
+	return NewRawQueryWith(registry.GetDBRegistry(), model, sql, args...)
+}
+
+// NewRawQueryWith is NewRawQuery for a model registered in reg
+func NewRawQueryWith(reg *registry.DBRegistry, model any, sql string, args ...any) *RawQuery {
+	sql, args = bindNamed(sql, args)
+	if model != nil {
+		tableMeta := reg.GetTableMeta(model)
+		resolved, err := resolveRaw(sql, tableMeta)
+		if err != nil {
+			panic(err.Error())
+		}
+		sql = tableRefPattern.ReplaceAllString(resolved, tableMeta.TableName)
+	}
+	if columnRefPattern.MatchString(sql) || tableRefPattern.MatchString(sql) {
+		panic("raw SQL references columns or table without a model: " + sql)
+	}
+	if err := checkPlaceholders(sql, len(args)); err != nil {
+		panic(err.Error())
+	}
+	return &RawQuery{sql: sql, args: args, dialect: DefaultDialect}
+}
+
+// Dialect sets the SQL dialect used to render placeholders
+func (q *RawQuery) Dialect(d Dialect) *RawQuery {
+	q.dialect = d
+	return q
+}
+
+// Build returns the query with placeholders rewritten for the dialect, and its arguments
+func (q *RawQuery) Build() (string, []any) {
+	return Rebind(q.dialect, q.sql), q.args
+}
+
+// DebugSQL returns the query with its arguments interpolated, for debugging only
+func (q *RawQuery) DebugSQL() string {
+	query, args := q.Build()
+	return DebugSQL(q.dialect, query, args)
+}
+
+// Fingerprint returns the fingerprint of the query
+func (q *RawQuery) Fingerprint() QueryFingerprint {
+	query, _ := q.Build()
+	return Fingerprint(query)
+}
@@ -0,0 +1,114 @@
+package querybuilder
+
+import (
+	"encoding/json"
+	"little-orm/internal/model"
+	"testing"
+)
+
+func TestRaw_InSelectBuilder(t *testing.T) {
+	setupTestRegistry()
+
+	query, args := NewSelectBuilder(model.User{}).
+		Select("ID").
+		SelectExpr(As(Raw("length({{col:Name}}) + ?", 1), "name_len")).
+		Where(And(Eq("ID", 5), Raw("lower({{ col:Email }}) = lower(?)", "A@B.C"), Eq("Name", "x"))).
+		Dialect(Postgres).
+		Build()
+
+	expected := "SELECT id, length(name) + $1 AS name_len FROM users WHERE id = $2 AND (lower(email) = lower($3)) AND name = $4"
+	if query != expected {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expected, query)
+	}
+	if len(args) != 4 || args[0] != 1 || args[1] != 5 || args[2] != "A@B.C" || args[3] != "x" {
+		t.Errorf("Expected args [1 5 A@B.C x], got %v", args)
+	}
+}
+
+func TestRaw_DoesNotModifyInput(t *testing.T) {
+	setupTestRegistry()
+
+	raw := Raw("{{col:Name}} = ?", "x")
+	NewSelectBuilder(model.User{}).Where(raw)
+	if raw.(*RawExpr).SQL != "{{col:Name}} = ?" {
+		t.Errorf("Expected raw SQL to be unchanged, got %s", raw.(*RawExpr).SQL)
+	}
+}
+
+func TestRaw_ValidationErrors(t *testing.T) {
+	setupTestRegistry()
+
+	validator := &ExprValidator{tableMeta: NewSelectBuilder(model.User{}).tableMeta}
+	for _, raw := range []Expr{
+		Raw("{{col:CreatedAt}} > ?", 1),
+		Raw("id = ? AND name = ?", 1),
+		Raw("name = '?'", "x"),
+	} {
+		if _, err := validator.Resolve(raw); err == nil {
+			t.Errorf("Expected error for %s", raw.(*RawExpr).SQL)
+		}
+	}
+}
+
+func TestRaw_UnresolvedToSQL_ShouldPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic for unresolved column reference")
+		}
+	}()
+	Raw("{{col:Name}} = ?", "x").ToSQL()
+}
+
+func TestRaw_NotSerializable(t *testing.T) {
+	if _, err := json.Marshal(Raw("1 = 1")); err == nil {
+		t.Error("Expected error when marshalling a raw expression")
+	}
+	if _, err := DecodeExpr([]byte(`{"type":"raw","sql":"1 = 1"}`), DecodeOptions{}); err == nil {
+		t.Error("Expected error when decoding a raw expression")
+	}
+}
+
+func TestRawQuery(t *testing.T) {
+	setupTestRegistry()
+
+	q := NewRawQuery(model.User{}, "SELECT {{col:ID}} FROM {{table}} WHERE {{col:Name}} = ? AND note = 'why?' LIMIT ?", "john", 10).
+		Dialect(Postgres)
+
+	var _ QueryBuilder = q
+	query, args := q.Build()
+	expected := "SELECT id FROM users WHERE name = $1 AND note = 'why?' LIMIT $2"
+	if query != expected {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expected, query)
+	}
+	if len(args) != 2 || args[0] != "john" || args[1] != 10 {
+		t.Errorf("Expected args [john 10], got %v", args)
+	}
+	if fp := q.Fingerprint().Normalized; fp != "SELECT id FROM users WHERE name = ? AND note = ? LIMIT ?" {
+		t.Errorf("Unexpected fingerprint %s", fp)
+	}
+}
+
+func TestNewRawQuery_ShouldPanic(t *testing.T) {
+	setupTestRegistry()
+
+	tests := []struct {
+		name  string
+		model any
+		sql   string
+		args  []any
+	}{
+		{"unknown field", model.User{}, "SELECT {{col:Nope}} FROM users", nil},
+		{"column without model", nil, "SELECT {{col:ID}} FROM users", nil},
+		{"arg count mismatch", nil, "SELECT 1 WHERE ? = ?", []any{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("Expected panic for %s", tt.sql)
+				}
+			}()
+			NewRawQuery(tt.model, tt.sql, tt.args...)
+		})
+	}
+}
@@ -2,8 +2,8 @@ package querybuilder
 
 import (
 	"fmt"
-	. "little-orm/internal/database/iterator"
 	"little-orm/internal/database/registry"
+	"strconv"
 	"strings"
 )
 
@@ -13,24 +13,29 @@ type SelectBuilder struct {
 	fields        []Expr
 	exprs         Expr
 	groupBy       []ColumnExpr
-	orderBy       []string
+	orderBy       []Expr
 	sortOrder     []SortOrder
 	limit         int
 	offset        int
-	args          []any
+	immutable     bool
+	dialect       Dialect
 	tableMeta     registry.TableMeta
 	exprValidator *ExprValidator
 }
 
-// NewSelectBuilder creates a new SELECT query builder for the given model
+// NewSelectBuilder creates a new SELECT query builder for the given model,
+// registered in the default registry
 func NewSelectBuilder(model any) *SelectBuilder {
-	// Get table registry and table meta
-	reg := registry.GetDBRegistry()
+	return NewSelectBuilderWith(registry.GetDBRegistry(), model)
+}
+
+// NewSelectBuilderWith creates a new SELECT query builder for a model registered in reg
+func NewSelectBuilderWith(reg *registry.DBRegistry, model any) *SelectBuilder {
 	tableMeta := reg.GetTableMeta(model)
 
-	// Init all fields
-	fields := make([]Expr, 0, len(tableMeta.Columns))
-	for _, col := range tableMeta.Columns {
+	// Init all fields, in struct declaration order
+	fields := make([]Expr, 0, len(tableMeta.OrderedColumns))
+	for _, col := range tableMeta.OrderedColumns {
 		fields = append(fields, C(col.DBTag))
 	}
 
@@ -38,12 +43,40 @@ func NewSelectBuilder(model any) *SelectBuilder {
 		tableMeta:     tableMeta,
 		table:         tableMeta.TableName,
 		fields:        fields,
+		dialect:       DefaultDialect,
 		exprValidator: &ExprValidator{tableMeta: tableMeta},
 	}
 }
 
+// Clone returns an independent copy of the builder; changes to one don't affect the other
+func (b *SelectBuilder) Clone() *SelectBuilder {
+	cp := *b
+	cp.fields = append([]Expr(nil), b.fields...)
+	cp.groupBy = append([]ColumnExpr(nil), b.groupBy...)
+	cp.orderBy = append([]Expr(nil), b.orderBy...)
+	cp.sortOrder = append([]SortOrder(nil), b.sortOrder...)
+	return &cp
+}
+
+// Immutable returns a copy of the builder on which every chained call returns a new
+// builder, so a base query can be shared and extended concurrently
+func (b *SelectBuilder) Immutable() *SelectBuilder {
+	cp := b.Clone()
+	cp.immutable = true
+	return cp
+}
+
+// writable returns the builder to modify: a clone in immutable mode, the receiver otherwise
+func (b *SelectBuilder) writable() *SelectBuilder {
+	if b.immutable {
+		return b.Clone()
+	}
+	return b
+}
+
 // Select specifies which fields to select (if not called, selects all fields)
 func (b *SelectBuilder) Select(fields ...string) *SelectBuilder {
+	b = b.writable()
 	dbTags := []Expr{}
 	for _, field := range fields {
 		fieldMeta, ok := b.tableMeta.Columns[field]
@@ -57,8 +90,21 @@ func (b *SelectBuilder) Select(fields ...string) *SelectBuilder {
 	return b
 }
 
+// SelectExpr appends computed expressions (e.g. As(Rank(...), "rank")) to the selected fields
+func (b *SelectBuilder) SelectExpr(exprs ...Expr) *SelectBuilder {
+	b = b.writable()
+	for _, e := range exprs {
+		if err := b.exprValidator.ValidateAndTransform(&e); err != nil {
+			panic(err.Error())
+		}
+		b.fields = append(b.fields, e)
+	}
+	return b
+}
+
 // Where adds WHERE clause to the query
 func (b *SelectBuilder) Where(e Expr) *SelectBuilder {
+	b = b.writable()
 	if err := b.exprValidator.ValidateAndTransform(&e); err != nil {
 		panic(err.Error())
 	}
@@ -66,98 +112,204 @@ func (b *SelectBuilder) Where(e Expr) *SelectBuilder {
 	return b
 }
 
+// AndWhere combines e with the existing WHERE clause using AND
+func (b *SelectBuilder) AndWhere(e Expr) *SelectBuilder {
+	b = b.writable()
+	if err := b.exprValidator.ValidateAndTransform(&e); err != nil {
+		panic(err.Error())
+	}
+	if b.exprs == nil {
+		b.exprs = e
+	} else if e != nil {
+		b.exprs = B(OpAnd, b.exprs, e)
+	}
+	return b
+}
+
 // OrderBy adds ORDER BY clause to the query
 func (b *SelectBuilder) OrderBy(order string, sortOrder SortOrder) *SelectBuilder {
-	b.orderBy = append(b.orderBy, order)
+	b = b.writable()
+	b.orderBy = append(b.orderBy, C(order))
+	b.sortOrder = append(b.sortOrder, sortOrder)
+	return b
+}
+
+// OrderByExpr adds a validated expression (e.g. Rank(...)) to the ORDER BY clause
+func (b *SelectBuilder) OrderByExpr(e Expr, sortOrder SortOrder) *SelectBuilder {
+	b = b.writable()
+	if err := b.exprValidator.ValidateAndTransform(&e); err != nil {
+		panic(err.Error())
+	}
+	b.orderBy = append(b.orderBy, e)
 	b.sortOrder = append(b.sortOrder, sortOrder)
 	return b
 }
 
 // Limit sets the LIMIT clause
 func (b *SelectBuilder) Limit(n int) *SelectBuilder {
+	b = b.writable()
 	b.limit = n
 	return b
 }
 
 // Offset sets the OFFSET clause
 func (b *SelectBuilder) Offset(m int) *SelectBuilder {
+	b = b.writable()
 	b.offset = m
 	return b
 }
 
+// Dialect sets the SQL dialect used to render placeholders
+func (b *SelectBuilder) Dialect(d Dialect) *SelectBuilder {
+	b = b.writable()
+	b.dialect = d
+	return b
+}
+
 // Build constructs the final SQL query and returns it with arguments
 func (b *SelectBuilder) Build() (string, []any) {
-	query := b.buildSelectClause()
-	query += b.buildWhereClause()
-	query += b.buildOrderByClause()
-	query += b.buildLimitOffsetClause()
-	return query, b.args
+	clauses, args := b.buildClauses()
+	parts := make([]string, len(clauses))
+	for i, c := range clauses {
+		parts[i] = c.keyword + " " + c.sql
+	}
+	return Rebind(b.dialect, strings.Join(parts, " ")), args
+}
+
+// Format is like Build but renders one clause per line, with the items of
+// multi-item clauses and the top-level AND/OR terms of WHERE indented on their own line
+func (b *SelectBuilder) Format() (string, []any) {
+	clauses, args := b.buildClauses()
+	var sb strings.Builder
+	for i, c := range clauses {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(c.keyword)
+		if c.lines == nil {
+			sb.WriteString(" " + c.sql)
+			continue
+		}
+		for _, line := range c.lines {
+			sb.WriteString("\n" + formatIndent + line)
+		}
+	}
+	return Rebind(b.dialect, sb.String()), args
+}
+
+// DebugSQL returns the formatted query with its arguments interpolated as literals of the
+// builder's dialect. The result is meant for logs and debugging only, never for execution.
+func (b *SelectBuilder) DebugSQL() string {
+	query, args := b.Format()
+	return DebugSQL(b.dialect, query, args)
+}
+
+const formatIndent = "  "
+
+// clause is a rendered clause of a query; lines holds the items Format puts on their own line
+type clause struct {
+	keyword string
+	sql     string
+	lines   []string
+}
+
+// buildClauses renders the clauses of the query in order, collecting their arguments
+func (b *SelectBuilder) buildClauses() ([]clause, []any) {
+	fields, args := b.buildSelectClause()
+	clauses := []clause{fields, {keyword: "FROM", sql: b.table}}
+
+	if where, whereArgs, ok := b.buildWhereClause(); ok {
+		clauses = append(clauses, where)
+		args = append(args, whereArgs...)
+	}
+	if orderBy, orderByArgs, ok := b.buildOrderByClause(); ok {
+		clauses = append(clauses, orderBy)
+		args = append(args, orderByArgs...)
+	}
+	if b.limit > 0 {
+		clauses = append(clauses, clause{keyword: "LIMIT", sql: strconv.Itoa(b.limit)})
+	}
+	if b.offset > 0 {
+		clauses = append(clauses, clause{keyword: "OFFSET", sql: strconv.Itoa(b.offset)})
+	}
+	return clauses, args
 }
 
 // buildWhereClause constructs the WHERE clause
-func (b *SelectBuilder) buildWhereClause() string {
+func (b *SelectBuilder) buildWhereClause() (clause, []any, bool) {
 	if b.exprs == nil {
-		return ""
+		return clause{}, nil, false
 	}
 	whereClause, args := b.exprs.ToSQL()
-	b.args = args
-	return " WHERE " + whereClause
-}
 
-// buildSelectClause constructs the SELECT clause
-func (b *SelectBuilder) buildSelectClause() string {
-	cols := make([]*ColumnExpr, 0, len(b.fields))
-	for _, f := range b.fields {
-		switch v := f.(type) {
-		case *ColumnExpr:
-			cols = append(cols, v)
-		}
+	// Split a top-level AND/OR chain into one term per line
+	var lines []string
+	var op Op
+	switch e := b.exprs.(type) {
+	case *BinaryExpr:
+		op = e.Operator
+	case *LogicalExpr:
+		op = e.Operator
 	}
-	// Giả sử b.fields có kiểu []*ColumnExpr
-	expr, ok := (b.fields).([]*ColumnExpr)
-	if ok {
-		names := make([]string, 0, len(expr))
-		names = NewIterator(cols).Select(func(c ColumnExpr) string {
-			return c.Name
-		}).ToList()
-		fieldsStr := "*"
-		if len(expr) > 0 {
-			fieldsStr = strings.Join(names, ", ")
+	if op == OpAnd || op == OpOr {
+		prec := precedence(b.exprs)
+		for i, term := range flattenLogical(op, []Expr{b.exprs}) {
+			sql, _ := operandSQL(prec, term)
+			if i > 0 {
+				sql = string(op) + " " + sql
+			}
+			lines = append(lines, sql)
 		}
-		return fmt.Sprintf("SELECT %s FROM %s", fieldsStr, b.table)
+	} else {
+		lines = []string{whereClause}
 	}
+	return clause{keyword: "WHERE", sql: whereClause, lines: lines}, args, true
+}
 
-	// Nếu không phải []*ColumnExpr, có thể xử lý khác hoặc trả về lỗi
-	return ""
+// buildSelectClause constructs the SELECT clause
+func (b *SelectBuilder) buildSelectClause() (clause, []any) {
+	if len(b.fields) == 0 {
+		return clause{keyword: "SELECT", sql: "*"}, nil
+	}
 
+	names := make([]string, 0, len(b.fields))
+	var args []any
+	for _, f := range b.fields {
+		sql, fieldArgs := f.ToSQL()
+		names = append(names, sql)
+		args = append(args, fieldArgs...)
+	}
+	return clause{keyword: "SELECT", sql: strings.Join(names, ", "), lines: withCommas(names)}, args
 }
 
 // buildOrderByClause constructs the ORDER BY clause
-func (b *SelectBuilder) buildOrderByClause() string {
+func (b *SelectBuilder) buildOrderByClause() (clause, []any, bool) {
 	if len(b.orderBy) == 0 {
-		return ""
+		return clause{}, nil, false
 	}
 
 	orders := make([]string, len(b.orderBy))
+	var args []any
 	for i := range b.orderBy {
-		ord := b.orderBy[i]
+		ord, ordArgs := b.orderBy[i].ToSQL()
 		if i < len(b.sortOrder) {
 			ord += " " + string(b.sortOrder[i])
 		}
 		orders[i] = ord
+		args = append(args, ordArgs...)
 	}
 
-	return " ORDER BY " + strings.Join(orders, ", ")
+	return clause{keyword: "ORDER BY", sql: strings.Join(orders, ", "), lines: withCommas(orders)}, args, true
 }
 
-// buildLimitOffsetClause constructs the LIMIT and OFFSET clauses
-func (b *SelectBuilder) buildLimitOffsetClause() string {
-	result := ""
-	if b.limit > 0 {
-		result += fmt.Sprintf(" LIMIT %d", b.limit)
-	}
-	if b.offset > 0 {
-		result += fmt.Sprintf(" OFFSET %d", b.offset)
+// withCommas returns a copy of items with a trailing comma on all but the last
+func withCommas(items []string) []string {
+	lines := make([]string, len(items))
+	for i, item := range items {
+		lines[i] = item
+		if i < len(items)-1 {
+			lines[i] += ","
+		}
 	}
-	return result
+	return lines
 }
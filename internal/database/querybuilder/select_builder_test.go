@@ -2,8 +2,11 @@ package querybuilder
 
 import (
 	"fmt"
+	"little-orm/internal/database/registry"
 	"little-orm/internal/model"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -25,13 +28,36 @@ func TestNewSelectBuilder(t *testing.T) {
 	}
 }
 
+func TestNewSelectBuilderWith(t *testing.T) {
+	t.Parallel()
+
+	reg := registry.New()
+	reg.SetNamingStrategy(registry.SnakeCaseNaming{Schema: "chat"})
+	if err := reg.Register(model.User{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, _ := NewSelectBuilderWith(reg, model.User{}).Select("ID").Build()
+	expected := "SELECT id FROM chat.users"
+	if query != expected {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expected, query)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic for a model not registered in the given registry")
+		}
+	}()
+	NewSelectBuilderWith(reg, model.Message{})
+}
+
 func TestSelectBuilder_Build_Simple(t *testing.T) {
 	setupTestRegistry()
 
 	builder := NewSelectBuilder(model.User{})
 	query, args := builder.Build()
 
-	// Check query structure (field order may vary due to map iteration)
+	// Check query structure
 	if !strings.Contains(query, "SELECT") {
 		t.Error("Expected query to contain SELECT")
 	}
@@ -49,6 +75,18 @@ func TestSelectBuilder_Build_Simple(t *testing.T) {
 	}
 }
 
+func TestSelectBuilder_Build_DefaultFieldOrder(t *testing.T) {
+	setupTestRegistry()
+
+	expected := "SELECT id, email, name, password FROM users"
+	for i := 0; i < 20; i++ {
+		query, _ := NewSelectBuilder(model.User{}).Build()
+		if query != expected {
+			t.Fatalf("Expected fields in declaration order:\n%s\nGot:\n%s", expected, query)
+		}
+	}
+}
+
 func TestSelectBuilder_Where(t *testing.T) {
 	setupTestRegistry()
 
@@ -87,9 +125,9 @@ func TestSelectBuilder_Where_Multiple(t *testing.T) {
 		},
 	}).Build()
 
-	// Check query structure (field order may vary due to map iteration)
+	// Check query structure
 	if !strings.Contains(query, "SELECT") || !strings.Contains(query, "FROM users") ||
-		!strings.Contains(query, "WHERE (email = ? AND name = ?)") {
+		!strings.Contains(query, "WHERE email = ? AND name = ?") {
 		t.Errorf("Unexpected query: %s", query)
 	}
 
@@ -210,7 +248,7 @@ func TestSelectBuilder_ComplexQuery(t *testing.T) {
 
 	// Check all parts are present (field order may vary due to map iteration)
 	if !strings.Contains(query, "SELECT") || !strings.Contains(query, "FROM users") ||
-		!strings.Contains(query, "WHERE ((email LIKE ? AND name != ?) AND id IS NOT NULL)") ||
+		!strings.Contains(query, "WHERE email LIKE ? AND name != ? AND id IS NOT NULL") ||
 		!strings.Contains(query, "ORDER BY name ASC, id DESC") ||
 		!strings.Contains(query, "LIMIT 25") || !strings.Contains(query, "OFFSET 50") {
 		t.Errorf("Unexpected query: %s", query)
@@ -429,12 +467,12 @@ func TestSelectBuilder_Where_OpIn(t *testing.T) {
 		Right:    &LiteralExpr{Value: []int{1, 2, 3}},
 	}).Build()
 
-	if !strings.Contains(query, "WHERE (id IN ?)") {
-		t.Errorf("Expected WHERE clause with (id IN ?), got: %s", query)
+	if !strings.Contains(query, "WHERE id IN (?, ?, ?)") {
+		t.Errorf("Expected WHERE clause with id IN (?, ?, ?), got: %s", query)
 	}
 
-	if len(args) != 1 {
-		t.Errorf("Expected 1 arg, got %d", len(args))
+	if len(args) != 3 {
+		t.Errorf("Expected 3 args, got %d", len(args))
 	}
 }
 
@@ -448,12 +486,31 @@ func TestSelectBuilder_Where_OpNIn(t *testing.T) {
 		Right:    &LiteralExpr{Value: []int{4, 5, 6}},
 	}).Build()
 
-	if !strings.Contains(query, "WHERE (id NOT IN ?)") {
-		t.Errorf("Expected WHERE clause with (id NOT IN ?), got: %s", query)
+	if !strings.Contains(query, "WHERE id NOT IN (?, ?, ?)") {
+		t.Errorf("Expected WHERE clause with id NOT IN (?, ?, ?), got: %s", query)
+	}
+
+	if len(args) != 3 {
+		t.Errorf("Expected 3 args, got %d", len(args))
 	}
+}
 
-	if len(args) != 1 {
-		t.Errorf("Expected 1 arg, got %d", len(args))
+func TestSelectBuilder_Where_InPostgres(t *testing.T) {
+	setupTestRegistry()
+
+	// Build output binds as is, without the Find executor expanding the list
+	query, args := NewSelectBuilder(model.User{}).
+		Dialect(Postgres).
+		Select("ID").
+		Where(And(In("ID", []int{1, 2}), NotIn("Name", []string{"a"}), Eq("Email", "x"))).
+		Build()
+
+	expected := "SELECT id FROM users WHERE id IN ($1, $2) AND name NOT IN ($3) AND email = $4"
+	if query != expected {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expected, query)
+	}
+	if !reflect.DeepEqual(args, []any{1, 2, "a", "x"}) {
+		t.Errorf("Expected args [1 2 a x], got %v", args)
 	}
 }
 
@@ -476,8 +533,8 @@ func TestSelectBuilder_Where_OpOr(t *testing.T) {
 		},
 	}).Build()
 
-	if !strings.Contains(query, "WHERE (name = ? OR name = ?)") {
-		t.Errorf("Expected WHERE clause with (name = ? OR name = ?), got: %s", query)
+	if !strings.Contains(query, "WHERE name = ? OR name = ?") {
+		t.Errorf("Expected WHERE clause with name = ? OR name = ?, got: %s", query)
 	}
 
 	if len(args) != 2 || args[0] != "John" || args[1] != "Jane" {
@@ -513,7 +570,7 @@ func TestSelectBuilder_Where_ComplexNested(t *testing.T) {
 		},
 	}).Build()
 
-	if !strings.Contains(query, "WHERE ((email LIKE ? OR email LIKE ?) AND id > ?)") {
+	if !strings.Contains(query, "WHERE (email LIKE ? OR email LIKE ?) AND id > ?") {
 		t.Errorf("Expected WHERE clause with nested conditions, got: %s", query)
 	}
 
@@ -531,7 +588,7 @@ func TestSelectBuilder_Select_SpecificFields(t *testing.T) {
 	setupTestRegistry()
 
 	builder := NewSelectBuilder(model.User{})
-	builder.fields = []string{} // Clear default fields
+	builder.fields = []Expr{} // Clear default fields
 	query, _ := builder.Select("ID", "Email").Build()
 
 	expectedQuery := "SELECT id, email FROM users"
@@ -702,7 +759,7 @@ func TestSelectBuilder_Where_IsNullWithAnd(t *testing.T) {
 		},
 	}).Build()
 
-	if !strings.Contains(query, "WHERE (id > ? AND email IS NULL)") {
+	if !strings.Contains(query, "WHERE id > ? AND email IS NULL") {
 		t.Errorf("Expected WHERE clause combining > and IS NULL, got: %s", query)
 	}
 
@@ -730,7 +787,7 @@ func TestSelectBuilder_Where_BetweenWithOr(t *testing.T) {
 		},
 	}).Build()
 
-	if !strings.Contains(query, "WHERE (id BETWEEN ? AND ? OR id BETWEEN ? AND ?)") {
+	if !strings.Contains(query, "WHERE id BETWEEN ? AND ? OR id BETWEEN ? AND ?") {
 		t.Errorf("Expected WHERE clause with BETWEEN OR BETWEEN, got: %s", query)
 	}
 
@@ -766,7 +823,7 @@ func TestSelectBuilder_Where_NotWithComplexExpr(t *testing.T) {
 		},
 	}).Build()
 
-	if !strings.Contains(query, "WHERE NOT ((id > ? AND name = ?))") {
+	if !strings.Contains(query, "WHERE NOT (id > ? AND name = ?)") {
 		t.Errorf("Expected WHERE clause with NOT and complex expression, got: %s", query)
 	}
 
@@ -812,19 +869,13 @@ func TestSelectBuilder_Where_OpIn_MultipleValues(t *testing.T) {
 		Right:    &LiteralExpr{Value: []int{1, 2, 3, 4, 5}},
 	}).Build()
 
-	if !strings.Contains(query, "WHERE (id IN ?)") {
-		t.Errorf("Expected WHERE clause with (id IN ?), got: %s", query)
+	if !strings.Contains(query, "WHERE id IN (?, ?, ?, ?, ?)") {
+		t.Errorf("Expected WHERE clause with id IN (?, ?, ?, ?, ?), got: %s", query)
 	}
 
-	if len(args) != 1 {
-		t.Errorf("Expected 1 arg (slice), got %d", len(args))
-	}
-
-	// Verify the slice value
-	if argSlice, ok := args[0].([]int); !ok {
-		t.Errorf("Expected arg to be []int, got %T", args[0])
-	} else if len(argSlice) != 5 {
-		t.Errorf("Expected slice with 5 elements, got %d", len(argSlice))
+	// Each element is bound on its own
+	if !reflect.DeepEqual(args, []any{1, 2, 3, 4, 5}) {
+		t.Errorf("Expected args [1 2 3 4 5], got %v", args)
 	}
 }
 
@@ -1124,3 +1175,169 @@ func TestSelectBuilder_OrderBy_DuplicateField(t *testing.T) {
 
 	// This test documents current behavior - duplicates are allowed
 }
+
+// ==================== CLONE / IMMUTABLE TESTS ====================
+
+// Test Clone produces an independent builder
+func TestSelectBuilder_Clone(t *testing.T) {
+	setupTestRegistry()
+
+	base := NewSelectBuilder(model.User{}).Select("ID").Where(Gt("ID", 10)).OrderBy("id", Ascending)
+	clone := base.Clone().OrderBy("name", Descending).Limit(5)
+
+	baseQuery, _ := base.Build()
+	cloneQuery, _ := clone.Build()
+
+	if baseQuery != "SELECT id FROM users WHERE id > ? ORDER BY id ASC" {
+		t.Errorf("Expected base builder to be unchanged, got: %s", baseQuery)
+	}
+
+	if cloneQuery != "SELECT id FROM users WHERE id > ? ORDER BY id ASC, name DESC LIMIT 5" {
+		t.Errorf("Unexpected clone query: %s", cloneQuery)
+	}
+}
+
+// Test every chained call on an immutable builder returns a new builder
+func TestSelectBuilder_Immutable_ReturnsNewBuilder(t *testing.T) {
+	setupTestRegistry()
+
+	base := NewSelectBuilder(model.User{}).Select("ID", "Name").Where(IsNotNull("Email")).Immutable()
+
+	limited := base.Limit(10)
+	if limited == base {
+		t.Error("Expected Limit on immutable builder to return a new builder")
+	}
+
+	sorted := base.OrderBy("name", Ascending)
+	if sorted == base || sorted == limited {
+		t.Error("Expected OrderBy on immutable builder to return a new builder")
+	}
+
+	baseQuery, _ := base.Build()
+	if baseQuery != "SELECT id, name FROM users WHERE email IS NOT NULL" {
+		t.Errorf("Expected base query to be unchanged, got: %s", baseQuery)
+	}
+
+	limitedQuery, _ := limited.Build()
+	if limitedQuery != "SELECT id, name FROM users WHERE email IS NOT NULL LIMIT 10" {
+		t.Errorf("Unexpected limited query: %s", limitedQuery)
+	}
+
+	sortedQuery, _ := sorted.Build()
+	if sortedQuery != "SELECT id, name FROM users WHERE email IS NOT NULL ORDER BY name ASC" {
+		t.Errorf("Unexpected sorted query: %s", sortedQuery)
+	}
+}
+
+// Test per-request variants derived concurrently from a shared base (run with -race)
+func TestSelectBuilder_Immutable_ConcurrentVariants(t *testing.T) {
+	setupTestRegistry()
+
+	baseActiveUsers := NewSelectBuilder(model.User{}).
+		Select("ID", "Email").
+		Where(IsNotNull("Email")).
+		OrderBy("id", Ascending).
+		Immutable()
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 20; i++ {
+		wg.Add(1)
+		go func(page int) {
+			defer wg.Done()
+			query, args := baseActiveUsers.
+				Where(And(IsNotNull("Email"), Gt("ID", page))).
+				OrderBy("email", Descending).
+				Limit(10).
+				Offset(page * 10).
+				Build()
+
+			expected := fmt.Sprintf("SELECT id, email FROM users WHERE email IS NOT NULL AND id > ? "+
+				"ORDER BY id ASC, email DESC LIMIT 10 OFFSET %d", page*10)
+			if query != expected {
+				t.Errorf("Expected query:\n%s\nGot:\n%s", expected, query)
+			}
+			if len(args) != 1 || args[0] != page {
+				t.Errorf("Expected args [%d], got %v", page, args)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	query, args := baseActiveUsers.Build()
+	if query != "SELECT id, email FROM users WHERE email IS NOT NULL ORDER BY id ASC" || len(args) != 0 {
+		t.Errorf("Expected base builder to be unchanged, got: %s %v", query, args)
+	}
+}
+
+// Test Build doesn't leave state behind between calls
+func TestSelectBuilder_Build_Repeatable(t *testing.T) {
+	setupTestRegistry()
+
+	builder := NewSelectBuilder(model.User{}).Where(Eq("ID", 1))
+
+	q1, a1 := builder.Build()
+	q2, a2 := builder.Build()
+
+	if q1 != q2 || len(a1) != 1 || len(a2) != 1 {
+		t.Errorf("Expected repeated Build calls to match, got %s %v and %s %v", q1, a1, q2, a2)
+	}
+}
+
+func TestSelectBuilder_Format(t *testing.T) {
+	setupTestRegistry()
+
+	builder := NewSelectBuilder(model.User{}).
+		Select("ID", "Name").
+		Where(And(Like("Name", "j%"), Or(Gt("ID", 10), IsNull("Email")))).
+		OrderBy("id", Descending).
+		OrderBy("name", Ascending).
+		Limit(20).
+		Offset(40).
+		Dialect(Postgres)
+
+	query, args := builder.Format()
+	expected := `SELECT
+  id,
+  name
+FROM users
+WHERE
+  name LIKE $1
+  AND (id > $2 OR email IS NULL)
+ORDER BY
+  id DESC,
+  name ASC
+LIMIT 20
+OFFSET 40`
+	if query != expected {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expected, query)
+	}
+
+	built, builtArgs := builder.Build()
+	if len(args) != 2 || len(builtArgs) != 2 || args[0] != builtArgs[0] || args[1] != builtArgs[1] {
+		t.Errorf("Expected Format and Build args to match, got %v and %v", args, builtArgs)
+	}
+	expectedBuilt := "SELECT id, name FROM users WHERE name LIKE $1 AND (id > $2 OR email IS NULL) ORDER BY id DESC, name ASC LIMIT 20 OFFSET 40"
+	if built != expectedBuilt {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedBuilt, built)
+	}
+}
+
+func TestSelectBuilder_DebugSQL(t *testing.T) {
+	setupTestRegistry()
+
+	got := NewSelectBuilder(model.User{}).
+		Select("ID").
+		Where(And(Eq("Name", "O'Brien"), In("ID", []int{1, 2}))).
+		Dialect(Postgres).
+		DebugSQL()
+
+	expected := debugSQLHeader + `SELECT
+  id
+FROM users
+WHERE
+  name = 'O''Brien'
+  AND id IN (1, 2)`
+	if got != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, got)
+	}
+}
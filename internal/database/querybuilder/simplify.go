@@ -0,0 +1,264 @@
+package querybuilder
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+)
+
+// Simplify returns an optimized copy of expr:
+//   - nil operands of AND/OR are dropped and nested chains are flattened into a LogicalExpr
+//   - identical operands of an AND/OR are deduplicated
+//   - NOT (NOT x) becomes x
+//   - IN / NOT IN with a single value become = / !=
+//   - comparisons between two number or two boolean literals are folded into a boolean literal
+//
+// It returns nil when the whole expression is always true, which Where treats as no filter.
+func Simplify(expr Expr) Expr {
+	simplified := Rewrite(expr, simplifyNode)
+	if v, ok := boolLiteral(simplified); ok && v {
+		return nil
+	}
+	return simplified
+}
+
+func simplifyNode(e Expr) Expr {
+	switch e := e.(type) {
+	case *BinaryExpr:
+		switch e.Operator {
+		case OpAnd, OpOr:
+			return simplifyLogical(e.Operator, []Expr{e.Left, e.Right})
+		case OpIn, OpNIn:
+			return simplifyIn(e)
+		case OpEq, OpNEq, OpGt, OpLt, OpGte, OpLte:
+			if folded, ok := foldComparison(e); ok {
+				return L(folded)
+			}
+		}
+	case *LogicalExpr:
+		return simplifyLogical(e.Operator, e.Operands)
+	case *UnaryExpr:
+		if e.Operator != OpNot {
+			return e
+		}
+		if inner, ok := e.Operand.(*UnaryExpr); ok && inner.Operator == OpNot {
+			return inner.Operand
+		}
+		if v, ok := boolLiteral(e.Operand); ok {
+			return L(!v)
+		}
+	}
+	return e
+}
+
+// simplifyLogical flattens, deduplicates and folds the operands of an AND/OR
+func simplifyLogical(op Op, operands []Expr) Expr {
+	// AND absorbs true and is decided by false; OR the other way around
+	identity := op == OpAnd
+
+	var flat []Expr
+	seen := make(map[string]bool)
+	for _, operand := range flattenLogical(op, operands) {
+		if v, ok := boolLiteral(operand); ok {
+			if v == identity {
+				continue
+			}
+			return L(!identity)
+		}
+		if key, ok := exprKey(operand); ok {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		flat = append(flat, operand)
+	}
+
+	switch len(flat) {
+	case 0:
+		return L(identity)
+	case 1:
+		return flat[0]
+	default:
+		return &LogicalExpr{Operator: op, Operands: flat}
+	}
+}
+
+// flattenLogical collects the operands of nested chains using the same operator
+func flattenLogical(op Op, operands []Expr) []Expr {
+	var flat []Expr
+	for _, operand := range operands {
+		switch o := operand.(type) {
+		case nil:
+		case *LogicalExpr:
+			if o.Operator == op {
+				flat = append(flat, flattenLogical(op, o.Operands)...)
+				continue
+			}
+			flat = append(flat, o)
+		case *BinaryExpr:
+			if o.Operator == op {
+				flat = append(flat, flattenLogical(op, []Expr{o.Left, o.Right})...)
+				continue
+			}
+			flat = append(flat, o)
+		default:
+			flat = append(flat, o)
+		}
+	}
+	return flat
+}
+
+// simplifyIn rewrites single-value IN lists to equality and folds empty ones
+func simplifyIn(e *BinaryExpr) Expr {
+	lit, ok := e.Right.(*LiteralExpr)
+	if !ok {
+		return e
+	}
+	v := reflect.ValueOf(lit.Value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return e
+	}
+
+	switch v.Len() {
+	case 0:
+		// x IN () is never true, x NOT IN () always is
+		return L(e.Operator == OpNIn)
+	case 1:
+		op := OpEq
+		if e.Operator == OpNIn {
+			op = OpNEq
+		}
+		return B(op, e.Left, L(v.Index(0).Interface()))
+	default:
+		return e
+	}
+}
+
+// foldComparison evaluates a comparison between two literals
+func foldComparison(e *BinaryExpr) (bool, bool) {
+	left, ok := e.Left.(*LiteralExpr)
+	if !ok {
+		return false, false
+	}
+	right, ok := e.Right.(*LiteralExpr)
+	if !ok {
+		return false, false
+	}
+
+	order, ok := compareValues(left.Value, right.Value)
+	if !ok {
+		return false, false
+	}
+	switch e.Operator {
+	case OpEq:
+		return order == 0, true
+	case OpNEq:
+		return order != 0, true
+	case OpGt:
+		return order > 0, true
+	case OpLt:
+		return order < 0, true
+	case OpGte:
+		return order >= 0, true
+	case OpLte:
+		return order <= 0, true
+	}
+	return false, false
+}
+
+// compareValues orders two numbers or two booleans. Strings are left to the database,
+// whose collation may order them differently or compare them case-insensitively.
+func compareValues(a, b any) (int, bool) {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if isNumber(va) || isNumber(vb) {
+		if !isNumber(va) || !isNumber(vb) {
+			return 0, false
+		}
+		// Integers compare exactly, floats only when one side is a float
+		if isFloat(va) || isFloat(vb) {
+			return cmp.Compare(toFloat(va), toFloat(vb)), true
+		}
+		return compareIntegers(va, vb), true
+	}
+	if va.Kind() == reflect.Bool && vb.Kind() == reflect.Bool {
+		if va.Bool() == vb.Bool() {
+			return 0, true
+		}
+		// Only equality is meaningful, order false before true
+		if vb.Bool() {
+			return -1, true
+		}
+		return 1, true
+	}
+	return 0, false
+}
+
+// compareIntegers orders two signed or unsigned integers without losing precision
+func compareIntegers(a, b reflect.Value) int {
+	if isUnsigned(a) && isUnsigned(b) {
+		return cmp.Compare(a.Uint(), b.Uint())
+	}
+	if isUnsigned(a) {
+		return -compareIntegers(b, a)
+	}
+	// a is signed; a negative a is below any unsigned b
+	if isUnsigned(b) {
+		if a.Int() < 0 {
+			return -1
+		}
+		return cmp.Compare(uint64(a.Int()), b.Uint())
+	}
+	return cmp.Compare(a.Int(), b.Int())
+}
+
+func isNumber(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return isUnsigned(v) || isFloat(v)
+}
+
+func isUnsigned(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func isFloat(v reflect.Value) bool {
+	return v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64
+}
+
+func toFloat(v reflect.Value) float64 {
+	switch {
+	case isFloat(v):
+		return v.Float()
+	case isUnsigned(v):
+		return float64(v.Uint())
+	}
+	return float64(v.Int())
+}
+
+// boolLiteral reports whether e is a constant true/false literal
+func boolLiteral(e Expr) (bool, bool) {
+	lit, ok := e.(*LiteralExpr)
+	if !ok {
+		return false, false
+	}
+	v, ok := lit.Value.(bool)
+	return v, ok
+}
+
+// exprKey renders e to a string identifying it structurally; invalid nodes have no key
+func exprKey(e Expr) (key string, ok bool) {
+	defer func() {
+		if recover() != nil {
+			key, ok = "", false
+		}
+	}()
+	sql, args := e.ToSQL()
+	return fmt.Sprintf("%s %#v", sql, args), true
+}
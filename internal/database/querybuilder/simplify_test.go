@@ -0,0 +1,179 @@
+package querybuilder
+
+import (
+	"little-orm/internal/model"
+	"testing"
+)
+
+func TestSimplify(t *testing.T) {
+	testCases := []struct {
+		name         string
+		expr         Expr
+		expectedSQL  string
+		expectedArgs int
+	}{
+		{
+			name:         "Flatten nested AND",
+			expr:         And(Eq("ID", 1), Eq("Name", "a"), Eq("Email", "b")),
+			expectedSQL:  "ID = ? AND Name = ? AND Email = ?",
+			expectedArgs: 3,
+		},
+		{
+			name:         "Nil operands dropped",
+			expr:         And(nil, Eq("ID", 1)),
+			expectedSQL:  "ID = ?",
+			expectedArgs: 1,
+		},
+		{
+			name:         "Double negation",
+			expr:         Not(Not(Gt("ID", 10))),
+			expectedSQL:  "ID > ?",
+			expectedArgs: 1,
+		},
+		{
+			name:         "Duplicate conjuncts",
+			expr:         Or(Eq("Name", "x"), Eq("Name", "x")),
+			expectedSQL:  "Name = ?",
+			expectedArgs: 1,
+		},
+		{
+			name:         "Different args are not duplicates",
+			expr:         Or(Eq("Name", "x"), Eq("Name", "y")),
+			expectedSQL:  "Name = ? OR Name = ?",
+			expectedArgs: 2,
+		},
+		{
+			name:         "Single element IN",
+			expr:         In("ID", []int{7}),
+			expectedSQL:  "ID = ?",
+			expectedArgs: 1,
+		},
+		{
+			name:         "Single element NOT IN",
+			expr:         NotIn("ID", []int{7}),
+			expectedSQL:  "ID != ?",
+			expectedArgs: 1,
+		},
+		{
+			name:         "Literal comparison folded away",
+			expr:         And(B(OpEq, L(1), L(1)), Like("Name", "J%")),
+			expectedSQL:  "Name LIKE ?",
+			expectedArgs: 1,
+		},
+		{
+			name:         "False literal decides AND",
+			expr:         And(B(OpGt, L(1), L(2)), Like("Name", "J%")),
+			expectedSQL:  "?",
+			expectedArgs: 1,
+		},
+		{
+			name:         "Mixed AND/OR keeps grouping",
+			expr:         And(Or(Eq("ID", 1), Or(Eq("ID", 2), Eq("ID", 3))), IsNull("Email")),
+			expectedSQL:  "(ID = ? OR ID = ? OR ID = ?) AND Email IS NULL",
+			expectedArgs: 3,
+		},
+		{
+			name:         "Empty IN",
+			expr:         Or(In("ID", []int{}), Eq("ID", 1)),
+			expectedSQL:  "ID = ?",
+			expectedArgs: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sql, args := Simplify(tc.expr).ToSQL()
+
+			if sql != tc.expectedSQL {
+				t.Errorf("Expected SQL:\n%s\nGot:\n%s", tc.expectedSQL, sql)
+			}
+
+			if len(args) != tc.expectedArgs {
+				t.Errorf("Expected %d args, got %d: %v", tc.expectedArgs, len(args), args)
+			}
+		})
+	}
+}
+
+func TestCompareValues(t *testing.T) {
+	testCases := []struct {
+		a, b     any
+		expected int
+	}{
+		{int64(1 << 53), int64(1<<53 + 1), -1},
+		{uint64(1<<64 - 1), uint64(1<<64 - 2), 1},
+		{int64(-1), uint64(1<<64 - 1), -1},
+		{uint64(1<<63 + 1), int64(1<<63 - 1), 1},
+		{int8(7), uint16(7), 0},
+		{1.5, 1, 1},
+	}
+
+	for _, tc := range testCases {
+		got, ok := compareValues(tc.a, tc.b)
+		if !ok || got != tc.expected {
+			t.Errorf("compareValues(%#v, %#v): expected %d, got %d (ok=%v)", tc.a, tc.b, tc.expected, got, ok)
+		}
+	}
+
+	if _, ok := compareValues(1, "1"); ok {
+		t.Error("Expected a number and a string not to compare")
+	}
+	if _, ok := compareValues("a", "a"); ok {
+		t.Error("Expected strings not to compare, their order depends on the collation")
+	}
+}
+
+func TestSimplify_KeepsStringComparisons(t *testing.T) {
+	// 'a' < 'B' is true under most collations, false in byte order
+	for _, expr := range []Expr{B(OpLt, L("a"), L("B")), B(OpEq, L("a"), L("A"))} {
+		if _, ok := Simplify(expr).(*BinaryExpr); !ok {
+			t.Errorf("Expected %#v not to be folded, got %#v", expr, Simplify(expr))
+		}
+	}
+}
+
+func TestSimplify_AlwaysTrue(t *testing.T) {
+	if e := Simplify(And(nil, B(OpEq, L(1), L(1)))); e != nil {
+		t.Errorf("Expected always-true expression to simplify to nil, got %#v", e)
+	}
+}
+
+func TestSimplify_DoesNotMutate(t *testing.T) {
+	expr := And(Eq("ID", 1), And(Eq("Name", "a"), Eq("Name", "a")))
+	before, _ := expr.ToSQL()
+
+	Simplify(expr)
+
+	after, _ := expr.ToSQL()
+	if before != after {
+		t.Errorf("Expected input to be unchanged, got %s then %s", before, after)
+	}
+}
+
+func TestSelectBuilder_Where_Simplified(t *testing.T) {
+	setupTestRegistry()
+
+	query, args := NewSelectBuilder(model.User{}).
+		Select("ID").
+		Where(Simplify(And(Gt("ID", 1), And(nil, Like("Name", "J%")), Not(Not(IsNotNull("Email")))))).
+		Build()
+
+	expected := "SELECT id FROM users WHERE id > ? AND name LIKE ? AND email IS NOT NULL"
+	if query != expected {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expected, query)
+	}
+
+	if len(args) != 2 {
+		t.Errorf("Expected 2 args, got %v", args)
+	}
+}
+
+func TestSelectBuilder_Where_SimplifiedToNothing(t *testing.T) {
+	setupTestRegistry()
+
+	query, _ := NewSelectBuilder(model.User{}).Select("ID").Where(Simplify(And(nil, nil))).Build()
+
+	if query != "SELECT id FROM users" {
+		t.Errorf("Expected no WHERE clause, got: %s", query)
+	}
+}
@@ -5,17 +5,11 @@ import (
 	"little-orm/internal/model"
 )
 
-// setupTestRegistry initializes the registry with test models
-// This is safe to call multiple times as it just ensures the model is registered
+// setupTestRegistry initializes the registry with test models.
+// Registering a model again replaces its metadata, so it is safe to call multiple times;
+// it panics when a model or one of its relations is invalid.
 func setupTestRegistry() {
-	reg := registry.GetDBRegistry()
-
-	// Defer recover in case model is already registered
-	defer func() {
-		recover()
-	}()
-
-	reg.Register(model.User{})
+	registry.GetDBRegistry().MustRegister(model.User{}, model.Message{})
 }
 
 // init ensures the test model is registered when the test package loads
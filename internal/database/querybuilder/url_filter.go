@@ -0,0 +1,414 @@
+package querybuilder
+
+import (
+	"fmt"
+	"little-orm/internal/database/registry"
+	"math"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FilterPolicy is the per-model allow-list used to translate URL query parameters
+// such as `?filter=name:like:john,id:gt:10&sort=-id&page[size]=20` into a SelectBuilder.
+//
+// Filter grammar (terms separated by ',' are AND-ed):
+//
+//	filter := term (',' term)*
+//	term   := ('and' | 'or') '(' filter ')' | field ':' op [':' value]
+//
+// Operators: eq, ne, gt, lt, gte, lte, like, in, nin, between, null, notnull.
+// List values (in, nin, between) are separated by ';'. A backslash escapes the next character.
+// Groups nest at most MaxFilterDepth levels deep.
+type FilterPolicy struct {
+	model           any
+	modelType       reflect.Type
+	reg             *registry.DBRegistry
+	tableMeta       registry.TableMeta
+	filterable      map[string]bool
+	sortable        map[string]bool
+	defaultPageSize int
+	maxPageSize     int
+}
+
+// Page size limits used when PageSize is not called
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// MaxFilterDepth is the deepest nesting of and()/or() groups accepted in a filter
+const MaxFilterDepth = 8
+
+// NewFilterPolicy creates a policy for model allowing no field until Filterable/Sortable are called
+func NewFilterPolicy(model any) *FilterPolicy {
+	return NewFilterPolicyWith(registry.GetDBRegistry(), model)
+}
+
+// NewFilterPolicyWith is NewFilterPolicy for a model registered in reg
+func NewFilterPolicyWith(reg *registry.DBRegistry, model any) *FilterPolicy {
+	tableMeta := reg.GetTableMeta(model)
+	t := reflect.TypeOf(model)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return &FilterPolicy{
+		model:           model,
+		modelType:       t,
+		reg:             reg,
+		tableMeta:       tableMeta,
+		filterable:      make(map[string]bool),
+		sortable:        make(map[string]bool),
+		defaultPageSize: DefaultPageSize,
+		maxPageSize:     MaxPageSize,
+	}
+}
+
+// Filterable allows filtering on the given Go fields
+func (p *FilterPolicy) Filterable(fields ...string) *FilterPolicy {
+	for _, field := range fields {
+		p.mustColumn(field)
+		p.filterable[field] = true
+	}
+	return p
+}
+
+// Sortable allows sorting on the given Go fields
+func (p *FilterPolicy) Sortable(fields ...string) *FilterPolicy {
+	for _, field := range fields {
+		p.mustColumn(field)
+		p.sortable[field] = true
+	}
+	return p
+}
+
+// PageSize sets the page size used when none is requested and the largest one accepted
+func (p *FilterPolicy) PageSize(defaultSize, maxSize int) *FilterPolicy {
+	p.defaultPageSize = defaultSize
+	p.maxPageSize = maxSize
+	return p
+}
+
+func (p *FilterPolicy) mustColumn(field string) {
+	if _, ok := p.tableMeta.Columns[field]; !ok {
+		panic(fmt.Sprintf("Field %s is not registered", field))
+	}
+}
+
+// ParseQuery builds a SelectBuilder for the policy's model from URL query values
+func (p *FilterPolicy) ParseQuery(values url.Values) (*SelectBuilder, error) {
+	return p.Apply(NewSelectBuilderWith(p.reg, p.model), values)
+}
+
+// Apply returns a copy of b with the filter, sort and page parameters of values added;
+// b itself is left unchanged, even when an error is returned
+func (p *FilterPolicy) Apply(b *SelectBuilder, values url.Values) (*SelectBuilder, error) {
+	b = b.Clone()
+	if raw := values.Get("filter"); raw != "" {
+		filter, err := p.ParseFilter(raw)
+		if err != nil {
+			return nil, err
+		}
+		b = b.AndWhere(filter)
+	}
+
+	if raw := values.Get("sort"); raw != "" {
+		for _, item := range strings.Split(raw, ",") {
+			order := Ascending
+			if strings.HasPrefix(item, "-") {
+				order = Descending
+				item = item[1:]
+			}
+			field, ok := p.lookup(item, p.sortable)
+			if !ok {
+				return nil, fmt.Errorf("sort: field '%s' is not sortable", item)
+			}
+			b = b.OrderByExpr(C(field), order)
+		}
+	}
+
+	size := p.defaultPageSize
+	if raw := values.Get("page[size]"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("page[size]: invalid value '%s'", raw)
+		}
+		if n > p.maxPageSize {
+			return nil, fmt.Errorf("page[size]: %d exceeds the maximum of %d", n, p.maxPageSize)
+		}
+		size = n
+	}
+	number := 1
+	if raw := values.Get("page[number]"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("page[number]: invalid value '%s'", raw)
+		}
+		number = n
+	}
+	if size > 0 {
+		if number-1 > math.MaxInt/size {
+			return nil, fmt.Errorf("page[number]: %d is too large for page size %d", number, size)
+		}
+		b = b.Limit(size).Offset((number - 1) * size)
+	}
+	return b, nil
+}
+
+// lookup resolves a client field name, either the Go name or the db column, within allowed
+func (p *FilterPolicy) lookup(name string, allowed map[string]bool) (string, bool) {
+	if allowed[name] {
+		return name, true
+	}
+	if col, ok := p.tableMeta.ColumnByDBTag(name); ok && allowed[col.Name] {
+		return col.Name, true
+	}
+	return "", false
+}
+
+// ParseFilter parses the value of the filter parameter into an expression
+func (p *FilterPolicy) ParseFilter(raw string) (Expr, error) {
+	parser := &urlFilterParser{policy: p, src: raw}
+	expr, err := parser.parseList(OpAnd)
+	if err != nil {
+		return nil, err
+	}
+	if parser.pos < len(parser.src) {
+		return nil, parser.errorf("unexpected '%c'", parser.src[parser.pos])
+	}
+	return expr, nil
+}
+
+type urlFilterParser struct {
+	policy *FilterPolicy
+	src    string
+	pos    int
+	depth  int
+}
+
+func (p *urlFilterParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("filter: at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+// parseList parses ',' separated terms combined with op, stopping at ')' or the end
+func (p *urlFilterParser) parseList(op Op) (Expr, error) {
+	var terms []Expr
+	for {
+		term, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+		if p.pos < len(p.src) && p.src[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+		break
+	}
+	if op == OpOr {
+		return Or(terms...), nil
+	}
+	return And(terms...), nil
+}
+
+func (p *urlFilterParser) parseTerm() (Expr, error) {
+	for _, group := range []struct {
+		prefix string
+		op     Op
+	}{{"and(", OpAnd}, {"or(", OpOr}} {
+		if strings.HasPrefix(p.src[p.pos:], group.prefix) {
+			if p.depth == MaxFilterDepth {
+				return nil, p.errorf("groups nest deeper than %d levels", MaxFilterDepth)
+			}
+			p.pos += len(group.prefix)
+			p.depth++
+			expr, err := p.parseList(group.op)
+			p.depth--
+			if err != nil {
+				return nil, err
+			}
+			if p.pos >= len(p.src) || p.src[p.pos] != ')' {
+				return nil, p.errorf("missing ')'")
+			}
+			p.pos++
+			return expr, nil
+		}
+	}
+
+	start := p.pos
+	parts := p.readCondition()
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("filter: at offset %d: expected field:op[:value]", start)
+	}
+	return p.condition(start, parts)
+}
+
+// readCondition reads a condition up to the next unescaped ',' or ')' and splits it on ':'
+func (p *urlFilterParser) readCondition() []string {
+	var parts []string
+	var sb strings.Builder
+	for p.pos < len(p.src) {
+		ch := p.src[p.pos]
+		if ch == ',' || ch == ')' {
+			break
+		}
+		p.pos++
+		switch {
+		case ch == '\\' && p.pos < len(p.src):
+			// values keep their escapes until they are split into list items
+			if len(parts) == 2 {
+				sb.WriteByte('\\')
+			}
+			sb.WriteByte(p.src[p.pos])
+			p.pos++
+		case ch == ':' && len(parts) < 2:
+			parts = append(parts, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteByte(ch)
+		}
+	}
+	return append(parts, sb.String())
+}
+
+func (p *urlFilterParser) condition(offset int, parts []string) (Expr, error) {
+	errorf := func(format string, args ...any) error {
+		return fmt.Errorf("filter: at offset %d: %s", offset, fmt.Sprintf(format, args...))
+	}
+
+	field, ok := p.policy.lookup(parts[0], p.policy.filterable)
+	if !ok {
+		return nil, errorf("field '%s' is not filterable", parts[0])
+	}
+	op := parts[1]
+
+	switch op {
+	case "null":
+		return IsNull(field), nil
+	case "notnull":
+		return IsNotNull(field), nil
+	}
+	if len(parts) < 3 {
+		return nil, errorf("operator '%s' requires a value", op)
+	}
+	colType := p.policy.modelType.FieldByIndex(p.policy.tableMeta.Columns[field].Index).Type
+
+	switch op {
+	case "in", "nin", "between":
+		var values []any
+		for _, raw := range splitEscaped(parts[2], ';') {
+			v, err := convertValue(raw, colType)
+			if err != nil {
+				return nil, errorf("field '%s': %v", parts[0], err)
+			}
+			values = append(values, v)
+		}
+		switch {
+		case op == "between" && len(values) != 2:
+			return nil, errorf("between requires two values separated by ';'")
+		case op == "between":
+			return T(C(field), L(values[0]), L(values[1])), nil
+		case op == "in":
+			return B(OpIn, C(field), L(values)), nil
+		default:
+			return B(OpNIn, C(field), L(values)), nil
+		}
+	}
+
+	value := unescape(parts[2])
+	if op == "like" {
+		// LIKE fails at runtime on numbers, dates and other non-text columns
+		if elem := derefType(colType); elem.Kind() != reflect.String {
+			return nil, errorf("operator 'like' requires a string field, '%s' is %s", parts[0], elem)
+		}
+		return B(OpLike, C(field), L(value)), nil
+	}
+	ops := map[string]Op{"eq": OpEq, "ne": OpNEq, "gt": OpGt, "lt": OpLt, "gte": OpGte, "lte": OpLte}
+	sqlOp, ok := ops[op]
+	if !ok {
+		return nil, errorf("unknown operator '%s'", op)
+	}
+	v, err := convertValue(value, colType)
+	if err != nil {
+		return nil, errorf("field '%s': %v", parts[0], err)
+	}
+	return B(sqlOp, C(field), L(v)), nil
+}
+
+// derefType returns the type t points to, through any number of pointers
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// splitEscaped splits s on sep, honouring backslash escapes
+func splitEscaped(s string, sep byte) []string {
+	var parts []string
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s):
+			i++
+			sb.WriteByte(s[i])
+		case s[i] == sep:
+			parts = append(parts, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return append(parts, sb.String())
+}
+
+// unescape removes the backslash escapes of s
+func unescape(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// convertValue parses a raw value into a value of the Go type of the column (its element
+// type for pointers); types that aren't numbers, booleans or strings keep the raw string
+func convertValue(raw string, colType reflect.Type) (any, error) {
+	colType = derefType(colType)
+	v := reflect.New(colType).Elem()
+	switch colType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, colType.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer '%s'", raw)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, colType.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("invalid unsigned integer '%s'", raw)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, colType.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("invalid number '%s'", raw)
+		}
+		v.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean '%s'", raw)
+		}
+		v.SetBool(b)
+	case reflect.String:
+		v.SetString(raw)
+	default:
+		return raw, nil
+	}
+	return v.Interface(), nil
+}
@@ -0,0 +1,211 @@
+package querybuilder
+
+import (
+	"little-orm/internal/database/registry"
+	"little-orm/internal/model"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newUserPolicy() *FilterPolicy {
+	return NewFilterPolicy(model.User{}).
+		Filterable("ID", "Name", "Email").
+		Sortable("ID", "Name")
+}
+
+func TestFilterPolicy_ParseQuery(t *testing.T) {
+	setupTestRegistry()
+
+	values, _ := url.ParseQuery("filter=name:like:john,id:gt:10&sort=-id&page[size]=20&page[number]=3")
+	builder, err := newUserPolicy().ParseQuery(values)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := builder.Select("ID").Build()
+	expected := "SELECT id FROM users WHERE name LIKE ? AND id > ? ORDER BY id DESC LIMIT 20 OFFSET 40"
+	if query != expected {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expected, query)
+	}
+
+	if len(args) != 2 || args[0] != "john" || args[1] != 10 {
+		t.Errorf("Expected args [john 10], got %#v", args)
+	}
+}
+
+func TestFilterPolicy_ParseQuery_Registry(t *testing.T) {
+	t.Parallel()
+
+	reg := registry.New()
+	reg.SetNamingStrategy(registry.SnakeCaseNaming{Prefix: "app_"})
+	reg.Register(model.User{})
+
+	values, _ := url.ParseQuery("filter=id:eq:1")
+	builder, err := NewFilterPolicyWith(reg, model.User{}).Filterable("ID").ParseQuery(values)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, _ := builder.Select("ID").Build()
+	expected := "SELECT id FROM app_users WHERE id = ? LIMIT 20"
+	if query != expected {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expected, query)
+	}
+}
+
+func TestFilterPolicy_ParseFilter_Grouping(t *testing.T) {
+	setupTestRegistry()
+
+	expr, err := newUserPolicy().ParseFilter("or(name:eq:john,name:eq:jane),and(id:gte:1,id:lte:5),Email:notnull")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	sql, args := expr.ToSQL()
+	expected := "(Name = ? OR Name = ?) AND ID >= ? AND ID <= ? AND Email IS NOT NULL"
+	if sql != expected {
+		t.Errorf("Expected SQL:\n%s\nGot:\n%s", expected, sql)
+	}
+	if len(args) != 4 {
+		t.Errorf("Expected 4 args, got %v", args)
+	}
+}
+
+func TestFilterPolicy_ParseFilter_ListsAndEscapes(t *testing.T) {
+	setupTestRegistry()
+
+	expr, err := newUserPolicy().ParseFilter(`id:in:1;2;3,name:nin:a\;b;c,id:between:1;9,name:eq:x\,y\:z`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, args := expr.ToSQL()
+	expected := []any{1, 2, 3, "a;b", "c", 1, 9, "x,y:z"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected args %#v, got %#v", expected, args)
+	}
+}
+
+func TestFilterPolicy_Errors(t *testing.T) {
+	setupTestRegistry()
+
+	testCases := []struct {
+		name  string
+		query string
+		error string
+	}{
+		{name: "Field not allowed", query: "filter=password:eq:x", error: "field 'password' is not filterable"},
+		{name: "Go name not allowed", query: "filter=Password:eq:x", error: "field 'Password' is not filterable"},
+		{name: "Unknown operator", query: "filter=id:regex:x", error: "unknown operator 'regex'"},
+		{name: "Missing value", query: "filter=id:gt", error: "operator 'gt' requires a value"},
+		{name: "Invalid integer", query: "filter=id:gt:abc", error: "invalid integer 'abc'"},
+		{name: "Missing paren", query: "filter=or(id:eq:1", error: "missing ')'"},
+		{name: "Unexpected paren", query: "filter=id:eq:1)", error: "unexpected ')'"},
+		{name: "Malformed condition", query: "filter=id", error: "expected field:op[:value]"},
+		{name: "Like on a number", query: "filter=id:like:5", error: "operator 'like' requires a string field, 'id' is int"},
+		{name: "Between arity", query: "filter=id:between:1", error: "between requires two values"},
+		{name: "Sort not allowed", query: "sort=-email", error: "sort: field 'email' is not sortable"},
+		{name: "Page size too large", query: "page[size]=1000", error: "exceeds the maximum of 100"},
+		{name: "Invalid page number", query: "page[number]=0", error: "page[number]: invalid value '0'"},
+		{name: "Offset overflow", query: "page[number]=9223372036854775807", error: "is too large for page size 20"},
+		{name: "Nesting too deep", query: "filter=" + strings.Repeat("and(", MaxFilterDepth+1) + "id:eq:1" + strings.Repeat(")", MaxFilterDepth+1),
+			error: "groups nest deeper than 8 levels"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			values, _ := url.ParseQuery(tc.query)
+			_, err := newUserPolicy().ParseQuery(values)
+			if err == nil {
+				t.Fatalf("Expected error containing %q, got nil", tc.error)
+			}
+			if !strings.Contains(err.Error(), tc.error) {
+				t.Errorf("Expected error containing %q, got %q", tc.error, err.Error())
+			}
+		})
+	}
+}
+
+func TestFilterPolicy_Apply_KeepsBaseFilter(t *testing.T) {
+	setupTestRegistry()
+
+	base := NewSelectBuilder(model.User{}).Select("ID").Where(IsNotNull("Email")).Immutable()
+
+	values, _ := url.ParseQuery("filter=id:eq:7")
+	builder, err := newUserPolicy().PageSize(10, 50).Apply(base, values)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, _ := builder.Build()
+	expected := "SELECT id FROM users WHERE email IS NOT NULL AND id = ? LIMIT 10"
+	if query != expected {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expected, query)
+	}
+
+	baseQuery, _ := base.Build()
+	if baseQuery != "SELECT id FROM users WHERE email IS NOT NULL" {
+		t.Errorf("Expected base builder to be unchanged, got: %s", baseQuery)
+	}
+}
+
+func TestFilterPolicy_UnknownField_ShouldPanic(t *testing.T) {
+	setupTestRegistry()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic for unknown field, but didn't panic")
+		}
+	}()
+
+	NewFilterPolicy(model.User{}).Filterable("Salary")
+}
+
+func TestFilterPolicy_Apply_ErrorLeavesBuilderUnchanged(t *testing.T) {
+	setupTestRegistry()
+
+	base := NewSelectBuilder(model.User{}).Select("ID")
+
+	values, _ := url.ParseQuery("filter=id:eq:7&sort=email")
+	if _, err := newUserPolicy().Apply(base, values); err == nil {
+		t.Fatal("Expected error for a field that is not sortable")
+	}
+
+	query, _ := base.Build()
+	if query != "SELECT id FROM users" {
+		t.Errorf("Expected base builder to be unchanged, got: %s", query)
+	}
+}
+
+func TestFilterPolicy_ValueTypes(t *testing.T) {
+	t.Parallel()
+
+	type Level int16
+	type Reading struct {
+		ID     uint32   `db:"id,pk"`
+		Level  Level    `db:"level"`
+		Ratio  float32  `db:"ratio"`
+		Active *bool    `db:"active"`
+		Serial uint8    `db:"serial"`
+		Total  *float64 `db:"total"`
+	}
+	reg := registry.New()
+	reg.MustRegister(Reading{})
+	policy := NewFilterPolicyWith(reg, &Reading{}).Filterable("ID", "Level", "Ratio", "Active", "Serial")
+
+	expr, err := policy.ParseFilter("id:in:1;2,level:gt:-3,ratio:lt:0.5,active:eq:true")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_, args := expr.ToSQL()
+	expected := []any{uint32(1), uint32(2), Level(-3), float32(0.5), true}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected args %#v, got %#v", expected, args)
+	}
+
+	if _, err := policy.ParseFilter("serial:eq:256"); err == nil || !strings.Contains(err.Error(), "invalid unsigned integer '256'") {
+		t.Errorf("Expected an out of range error, got %v", err)
+	}
+}
@@ -5,56 +5,59 @@ import (
 	"little-orm/internal/database/registry"
 )
 
-// ExprValidator validates and transforms expressions
+// ExprValidator validates expressions and resolves Go field names to database columns
 type ExprValidator struct {
 	tableMeta registry.TableMeta
 }
 
-// ValidateAndTransform validates expression and transforms column names to database tags
-func (v *ExprValidator) ValidateAndTransform(expr *Expr) error {
-	switch expr := (*expr).(type) {
-	case *ColumnExpr:
-		colMeta, ok := v.tableMeta.Columns[expr.Name]
-		if !ok {
-			return fmt.Errorf("column '%s' not found in table '%s'", expr.Name, v.tableMeta.TableName)
-		}
-		expr.Name = colMeta.DBTag
-	case *BinaryExpr:
-		if expr.Left != nil {
-			if err := (*v).ValidateAndTransform(&expr.Left); err != nil {
-				return err
-			}
+// Resolve validates expr and returns a copy whose columns reference database tags.
+// The input tree is never modified, so it can be shared between builders and goroutines.
+func (v *ExprValidator) Resolve(expr Expr) (Expr, error) {
+	var err error
+	resolved := Rewrite(expr, func(e Expr) Expr {
+		if err != nil {
+			return e
 		}
-		if expr.Right != nil {
-			if err := (*v).ValidateAndTransform(&expr.Right); err != nil {
-				return err
+		switch e := e.(type) {
+		case *ColumnExpr:
+			colMeta, ok := v.tableMeta.Columns[e.Name]
+			if !ok {
+				err = fmt.Errorf("column '%s' not found in table '%s'", e.Name, v.tableMeta.TableName)
+				return e
 			}
-		}
-	case *UnaryExpr:
-		if expr.Operand != nil {
-			if err := (*v).ValidateAndTransform(&expr.Operand); err != nil {
-				return err
-			}
-		}
-	case *TernaryExpr:
-		if expr.Expr != nil {
-			if err := (*v).ValidateAndTransform(&expr.Expr); err != nil {
-				return err
+			return &ColumnExpr{Name: colMeta.DBTag}
+		case *FullTextExpr:
+			err = validateTextSearch(e)
+		case *AliasExpr:
+			if !identifierPattern.MatchString(e.Alias) {
+				err = fmt.Errorf("invalid alias '%s'", e.Alias)
 			}
-		}
-		if expr.Low != nil {
-			if err := (*v).ValidateAndTransform(&expr.Low); err != nil {
-				return err
+		case *ParamExpr:
+			if !identifierPattern.MatchString(e.Name) {
+				err = fmt.Errorf("invalid parameter name '%s'", e.Name)
 			}
-		}
-		if expr.High != nil {
-			if err := (*v).ValidateAndTransform(&expr.High); err != nil {
-				return err
+		case *RawExpr:
+			var sql string
+			if sql, err = resolveRaw(e.SQL, v.tableMeta); err == nil {
+				err = checkPlaceholders(sql, len(e.Args))
 			}
+			return &RawExpr{SQL: sql, Args: e.Args}
 		}
-	case *LiteralExpr:
-		return nil
-	default:
+		return e
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// ValidateAndTransform validates the expression pointed to by expr and replaces it
+// with its resolved copy; the original tree is left untouched
+func (v *ExprValidator) ValidateAndTransform(expr *Expr) error {
+	resolved, err := v.Resolve(*expr)
+	if err != nil {
+		return err
 	}
+	*expr = resolved
 	return nil
 }
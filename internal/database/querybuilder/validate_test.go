@@ -0,0 +1,77 @@
+package querybuilder
+
+import (
+	"little-orm/internal/model"
+	"sync"
+	"testing"
+)
+
+// activeUsers is a package-level filter shared by several builders
+var activeUsers = And(Gt("ID", 0), IsNotNull("Email"))
+
+func TestExprValidator_Resolve_DoesNotMutate(t *testing.T) {
+	setupTestRegistry()
+
+	expr := Eq("Email", "test@example.com")
+	validator := &ExprValidator{tableMeta: NewSelectBuilder(model.User{}).tableMeta}
+
+	resolved, err := validator.Resolve(expr)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if col := expr.(*BinaryExpr).Left.(*ColumnExpr); col.Name != "Email" {
+		t.Errorf("Expected original column to stay 'Email', got '%s'", col.Name)
+	}
+
+	if col := resolved.(*BinaryExpr).Left.(*ColumnExpr); col.Name != "email" {
+		t.Errorf("Expected resolved column to be 'email', got '%s'", col.Name)
+	}
+}
+
+func TestExprValidator_Resolve_InvalidColumn(t *testing.T) {
+	setupTestRegistry()
+
+	validator := &ExprValidator{tableMeta: NewSelectBuilder(model.User{}).tableMeta}
+
+	_, err := validator.Resolve(And(Eq("ID", 1), Eq("Unknown", 2)))
+	if err == nil {
+		t.Fatal("Expected error for unknown column")
+	}
+
+	expected := "column 'Unknown' not found in table 'users'"
+	if err.Error() != expected {
+		t.Errorf("Expected error %q, got %q", expected, err.Error())
+	}
+}
+
+func TestSelectBuilder_Where_ReuseExpression(t *testing.T) {
+	setupTestRegistry()
+
+	first, _ := NewSelectBuilder(model.User{}).Select("ID").Where(activeUsers).Build()
+	second, _ := NewSelectBuilder(model.User{}).Select("Name").Where(activeUsers).Build()
+
+	if first != "SELECT id FROM users WHERE id > ? AND email IS NOT NULL" {
+		t.Errorf("Unexpected first query: %s", first)
+	}
+	if second != "SELECT name FROM users WHERE id > ? AND email IS NOT NULL" {
+		t.Errorf("Unexpected second query: %s", second)
+	}
+}
+
+func TestSelectBuilder_Where_SharedExpressionConcurrently(t *testing.T) {
+	setupTestRegistry()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			query, _ := NewSelectBuilder(model.User{}).Select("ID").Where(activeUsers).Build()
+			if query != "SELECT id FROM users WHERE id > ? AND email IS NOT NULL" {
+				t.Errorf("Unexpected query: %s", query)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,122 @@
+package querybuilder
+
+// Visitor's Visit method is invoked for each expression encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children
+// of expr with w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(expr Expr) (w Visitor)
+}
+
+// CompositeExpr is implemented by expressions holding sub-expressions.
+// Walk and Rewrite traverse any node kind implementing it.
+type CompositeExpr interface {
+	Expr
+	// Children returns the sub-expressions in rendering order (entries may be nil)
+	Children() []Expr
+	// WithChildren returns a shallow copy of the expression with its children replaced
+	WithChildren(children []Expr) Expr
+}
+
+// Walk traverses an expression tree in depth-first order
+func Walk(v Visitor, expr Expr) {
+	if expr == nil {
+		return
+	}
+	if v = v.Visit(expr); v == nil {
+		return
+	}
+	if c, ok := expr.(CompositeExpr); ok {
+		for _, child := range c.Children() {
+			if child != nil {
+				Walk(v, child)
+			}
+		}
+	}
+	v.Visit(nil)
+}
+
+type inspector func(Expr) bool
+
+func (f inspector) Visit(expr Expr) Visitor {
+	if f(expr) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an expression tree in depth-first order, calling f for
+// each node and then f(nil) after its children. Children are skipped when f returns false.
+func Inspect(expr Expr, f func(Expr) bool) {
+	Walk(inspector(f), expr)
+}
+
+// Rewrite rebuilds an expression tree bottom-up, replacing every node by fn(node).
+// Composite nodes whose children changed are copied, so the input tree is never modified.
+func Rewrite(expr Expr, fn func(Expr) Expr) Expr {
+	if expr == nil {
+		return nil
+	}
+	if c, ok := expr.(CompositeExpr); ok {
+		children := c.Children()
+		rewritten := make([]Expr, len(children))
+		changed := false
+		for i, child := range children {
+			rewritten[i] = Rewrite(child, fn)
+			if rewritten[i] != child {
+				changed = true
+			}
+		}
+		if changed {
+			expr = c.WithChildren(rewritten)
+		}
+	}
+	return fn(expr)
+}
+
+func (u *UnaryExpr) Children() []Expr { return []Expr{u.Operand} }
+
+func (u *UnaryExpr) WithChildren(children []Expr) Expr {
+	cp := *u
+	cp.Operand = children[0]
+	return &cp
+}
+
+func (b *BinaryExpr) Children() []Expr { return []Expr{b.Left, b.Right} }
+
+func (b *BinaryExpr) WithChildren(children []Expr) Expr {
+	cp := *b
+	cp.Left, cp.Right = children[0], children[1]
+	return &cp
+}
+
+func (b *TernaryExpr) Children() []Expr { return []Expr{b.Expr, b.Low, b.High} }
+
+func (b *TernaryExpr) WithChildren(children []Expr) Expr {
+	cp := *b
+	cp.Expr, cp.Low, cp.High = children[0], children[1], children[2]
+	return &cp
+}
+
+func (a *AliasExpr) Children() []Expr { return []Expr{a.Expr} }
+
+func (a *AliasExpr) WithChildren(children []Expr) Expr {
+	cp := *a
+	cp.Expr = children[0]
+	return &cp
+}
+
+func (f *FullTextExpr) Children() []Expr { return []Expr{f.Column, f.Query} }
+
+func (f *FullTextExpr) WithChildren(children []Expr) Expr {
+	cp := *f
+	cp.Column, cp.Query = children[0], children[1]
+	return &cp
+}
+
+func (l *LogicalExpr) Children() []Expr { return append([]Expr(nil), l.Operands...) }
+
+func (l *LogicalExpr) WithChildren(children []Expr) Expr {
+	cp := *l
+	cp.Operands = children
+	return &cp
+}
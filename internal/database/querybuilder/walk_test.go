@@ -0,0 +1,116 @@
+package querybuilder
+
+import (
+	"little-orm/internal/model"
+	"reflect"
+	"testing"
+)
+
+func TestInspect_VisitsAllNodes(t *testing.T) {
+	expr := And(Eq("ID", 1), Not(IsNull("Email")), Between("ID", 1, 10))
+
+	var columns []string
+	nodes := 0
+	Inspect(expr, func(e Expr) bool {
+		if e == nil {
+			return true
+		}
+		nodes++
+		if c, ok := e.(*ColumnExpr); ok {
+			columns = append(columns, c.Name)
+		}
+		return true
+	})
+
+	expectedColumns := []string{"ID", "Email", "ID"}
+	if !reflect.DeepEqual(columns, expectedColumns) {
+		t.Errorf("Expected columns %v, got %v", expectedColumns, columns)
+	}
+
+	// 2 AND + Eq(3) + Not + IsNull(2) + Between(4)
+	if nodes != 12 {
+		t.Errorf("Expected 12 nodes, got %d", nodes)
+	}
+}
+
+func TestInspect_SkipChildren(t *testing.T) {
+	expr := Or(Not(Eq("ID", 1)), Eq("Name", "x"))
+
+	var columns []string
+	Inspect(expr, func(e Expr) bool {
+		if u, ok := e.(*UnaryExpr); ok && u.Operator == OpNot {
+			return false
+		}
+		if c, ok := e.(*ColumnExpr); ok {
+			columns = append(columns, c.Name)
+		}
+		return true
+	})
+
+	if !reflect.DeepEqual(columns, []string{"Name"}) {
+		t.Errorf("Expected only Name to be visited, got %v", columns)
+	}
+}
+
+type countingVisitor struct {
+	enter, leave int
+}
+
+func (v *countingVisitor) Visit(e Expr) Visitor {
+	if e == nil {
+		v.leave++
+	} else {
+		v.enter++
+	}
+	return v
+}
+
+func TestWalk_CallsVisitNilAfterChildren(t *testing.T) {
+	v := &countingVisitor{}
+	Walk(v, Eq("ID", 1))
+
+	if v.enter != 3 || v.leave != 3 {
+		t.Errorf("Expected 3 enters and 3 leaves, got %d and %d", v.enter, v.leave)
+	}
+}
+
+func TestRewrite_ColumnMasking(t *testing.T) {
+	setupTestRegistry()
+
+	original := And(Eq("Password", "secret"), Gt("ID", 10))
+
+	// Replace any comparison on Password by a constant false predicate
+	masked := Rewrite(original, func(e Expr) Expr {
+		if b, ok := e.(*BinaryExpr); ok {
+			if c, ok := b.Left.(*ColumnExpr); ok && c.Name == "Password" {
+				return Eq("ID", -1)
+			}
+		}
+		return e
+	})
+
+	query, args := NewSelectBuilder(model.User{}).Select("ID").Where(masked).Build()
+
+	expected := "SELECT id FROM users WHERE id = ? AND id > ?"
+	if query != expected {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expected, query)
+	}
+	if len(args) != 2 || args[0] != -1 || args[1] != 10 {
+		t.Errorf("Expected args [-1 10], got %v", args)
+	}
+
+	// The original tree is untouched
+	left := original.(*BinaryExpr).Left.(*BinaryExpr).Left.(*ColumnExpr)
+	if left.Name != "Password" {
+		t.Errorf("Expected original tree to keep Password, got %s", left.Name)
+	}
+}
+
+func TestRewrite_UnchangedTreeIsShared(t *testing.T) {
+	expr := And(Eq("ID", 1), Like("Name", "J%"))
+
+	result := Rewrite(expr, func(e Expr) Expr { return e })
+	if result != expr {
+		t.Error("Expected Rewrite with identity function to return the same tree")
+	}
+}
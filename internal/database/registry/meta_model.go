@@ -2,17 +2,104 @@ package registry
 
 type ColumnMeta struct {
 	DBTag string
-	Name  string
-	Type  string
-	Tag   string
+	// Name is the Go field name; fields of inline structs are qualified (e.g. "Address.Street")
+	Name string
+	Type string
+	Tag  string
+	// Path is the full Go field path, including embedded structs (e.g. "Timestamps.CreatedAt")
+	Path string
+	// Index is the field index sequence for reflect.Value.FieldByIndex
+	Index []int
+
+	// Options parsed from the db tag, e.g. db:"id,pk,autoincr"
+	PrimaryKey    bool
+	AutoIncrement bool
+	Default       string // SQL default expression, e.g. "now()"
+	ReadOnly      bool
+	Nullable      bool
+	Unique        bool
+	Size          int
+	OmitEmpty     bool
+}
+
+// Insertable reports whether INSERT statements should write the column;
+// auto-incremented and read-only columns are generated by the database
+func (c ColumnMeta) Insertable() bool {
+	return !c.AutoIncrement && !c.ReadOnly
 }
 
 type TableMeta struct {
 	TableName string
-	Columns   map[string]ColumnMeta
+	// Columns maps Go field names to their column
+	Columns map[string]ColumnMeta
+	// OrderedColumns lists the columns in struct field declaration order;
+	// use it wherever a column list is generated
+	OrderedColumns []ColumnMeta
+	// Relations maps Go field names to the relations declared on them with rel tags
+	Relations map[string]Relation
+
+	// byDBTag maps column names to Go field names
+	byDBTag map[string]string
+}
+
+// newTableMeta indexes columns, given in declaration order, by Go name and column name
+func newTableMeta(tableName string, columns []ColumnMeta) TableMeta {
+	t := TableMeta{
+		TableName:      tableName,
+		Columns:        make(map[string]ColumnMeta, len(columns)),
+		OrderedColumns: columns,
+		byDBTag:        make(map[string]string, len(columns)),
+	}
+	for _, col := range columns {
+		t.Columns[col.Name] = col
+		t.byDBTag[col.DBTag] = col.Name
+	}
+	return t
 }
 
 func (t TableMeta) HasColumn(columnName string) bool {
 	_, ok := t.Columns[columnName]
 	return ok
 }
+
+// Column returns the column of a Go field
+func (t TableMeta) Column(fieldName string) (ColumnMeta, bool) {
+	col, ok := t.Columns[fieldName]
+	return col, ok
+}
+
+// ColumnByDBTag returns the column with the given database name
+func (t TableMeta) ColumnByDBTag(dbTag string) (ColumnMeta, bool) {
+	if t.byDBTag == nil {
+		for _, col := range t.OrderedColumns {
+			if col.DBTag == dbTag {
+				return col, true
+			}
+		}
+		return ColumnMeta{}, false
+	}
+	name, ok := t.byDBTag[dbTag]
+	if !ok {
+		return ColumnMeta{}, false
+	}
+	return t.Columns[name], true
+}
+
+// ColumnNames returns the database column names in declaration order
+func (t TableMeta) ColumnNames() []string {
+	names := make([]string, len(t.OrderedColumns))
+	for i, col := range t.OrderedColumns {
+		names[i] = col.DBTag
+	}
+	return names
+}
+
+// PrimaryKey returns the column tagged pk, if any
+func (t TableMeta) PrimaryKey() (ColumnMeta, bool) {
+	for _, col := range t.OrderedColumns {
+		if col.PrimaryKey {
+			return col, true
+		}
+	}
+	return ColumnMeta{}, false
+}
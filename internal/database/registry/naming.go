@@ -0,0 +1,145 @@
+package registry
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy derives database names from Go type and field names
+type NamingStrategy interface {
+	// TableName returns the table name for a Go struct type name (e.g. "UserProfile")
+	TableName(typeName string) string
+	// ColumnName returns the column name for an untagged Go field name (e.g. "CreatedAt")
+	ColumnName(fieldName string) string
+}
+
+// Tabler is implemented by models overriding their table name
+type Tabler interface {
+	TableName() string
+}
+
+// LegacyNaming lowercases the type name and appends "s" (UserProfile -> userprofiles).
+// It is the default strategy, kept so existing table names don't change.
+type LegacyNaming struct{}
+
+func (LegacyNaming) TableName(typeName string) string {
+	return strings.ToLower(typeName) + "s"
+}
+
+func (LegacyNaming) ColumnName(fieldName string) string {
+	return ToSnakeCase(fieldName)
+}
+
+// SnakeCaseNaming converts type names to snake_case and pluralizes the last word
+// (UserProfile -> user_profiles, Category -> categories, Person -> people)
+type SnakeCaseNaming struct {
+	// Prefix is prepended to every table name (e.g. "app_")
+	Prefix string
+	// Schema qualifies every table name (e.g. "public" gives "public.users")
+	Schema string
+	// Singular disables pluralization
+	Singular bool
+}
+
+func (n SnakeCaseNaming) TableName(typeName string) string {
+	name := ToSnakeCase(typeName)
+	if !n.Singular {
+		i := strings.LastIndexByte(name, '_')
+		name = name[:i+1] + Pluralize(name[i+1:])
+	}
+	name = n.Prefix + name
+	if n.Schema != "" {
+		name = n.Schema + "." + name
+	}
+	return name
+}
+
+func (SnakeCaseNaming) ColumnName(fieldName string) string {
+	return ToSnakeCase(fieldName)
+}
+
+// DefaultNamingStrategy is used by registries without a configured strategy
+var DefaultNamingStrategy NamingStrategy = LegacyNaming{}
+
+// ToSnakeCase converts a Go identifier to snake_case, keeping acronyms together
+// (UserID -> user_id, HTTPRequest -> http_request)
+func ToSnakeCase(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]))
+			acronymEnd := i > 0 && unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || acronymEnd {
+				sb.WriteByte('_')
+			}
+			sb.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+var irregularPlurals = map[string]string{
+	"person": "people",
+	"man":    "men",
+	"woman":  "women",
+	"child":  "children",
+	"mouse":  "mice",
+	"goose":  "geese",
+	"tooth":  "teeth",
+	"foot":   "feet",
+	"ox":     "oxen",
+	"leaf":   "leaves",
+	"knife":  "knives",
+	"life":   "lives",
+	"wife":   "wives",
+	"half":   "halves",
+	"datum":  "data",
+	"index":  "indices",
+	"matrix": "matrices",
+	"vertex": "vertices",
+	"quiz":   "quizzes",
+	"hero":   "heroes",
+	"potato": "potatoes",
+	"tomato": "tomatoes",
+}
+
+var uncountables = map[string]bool{
+	"equipment": true, "information": true, "rice": true, "money": true, "species": true,
+	"series": true, "fish": true, "sheep": true, "deer": true, "news": true, "metadata": true,
+}
+
+// Pluralize returns the English plural of a lowercase word
+func Pluralize(word string) string {
+	if word == "" || uncountables[word] {
+		return word
+	}
+	if plural, ok := irregularPlurals[word]; ok {
+		return plural
+	}
+	switch {
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		return word[:len(word)-1] + "ies"
+	case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "x"), strings.HasSuffix(word, "z"),
+		strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		return word + "es"
+	}
+	return word + "s"
+}
+
+// getTableName resolves the table of a model type: a TableName() method wins over a
+// `table` struct tag, which wins over the naming strategy
+func getTableName(t *reflect.Type, naming NamingStrategy) string {
+	if tabler, ok := reflect.New(*t).Interface().(Tabler); ok {
+		return tabler.TableName()
+	}
+	for i := 0; i < (*t).NumField(); i++ {
+		if table := (*t).Field(i).Tag.Get("table"); table != "" {
+			return table
+		}
+	}
+	return naming.TableName((*t).Name())
+}
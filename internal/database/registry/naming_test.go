@@ -0,0 +1,130 @@
+package registry
+
+import (
+	"reflect"
+	"testing"
+)
+
+type UserProfile struct {
+	ID int `db:"id"`
+}
+
+type Category struct {
+	ID int `db:"id"`
+}
+
+// CustomTable overrides its table name with a method
+type CustomTable struct {
+	ID int `db:"id"`
+}
+
+func (CustomTable) TableName() string { return "legacy_custom" }
+
+// PointerTable overrides its table name with a pointer receiver
+type PointerTable struct {
+	ID int `db:"id"`
+}
+
+func (*PointerTable) TableName() string { return "pointer_tables" }
+
+// TaggedTable sets its table name with a struct tag
+type TaggedTable struct {
+	_  struct{} `table:"tagged"`
+	ID int      `db:"id"`
+}
+
+func TestToSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"User":        "user",
+		"UserProfile": "user_profile",
+		"UserID":      "user_id",
+		"ID":          "id",
+		"HTTPRequest": "http_request",
+		"OAuth2Token": "o_auth2_token",
+		"Address2":    "address2",
+		"createdAt":   "created_at",
+	}
+	for input, expected := range tests {
+		if got := ToSnakeCase(input); got != expected {
+			t.Errorf("Expected ToSnakeCase(%q) to be '%s', got '%s'", input, expected, got)
+		}
+	}
+}
+
+func TestPluralize(t *testing.T) {
+	tests := map[string]string{
+		"user":     "users",
+		"category": "categories",
+		"day":      "days",
+		"box":      "boxes",
+		"match":    "matches",
+		"address":  "addresses",
+		"person":   "people",
+		"child":    "children",
+		"news":     "news",
+		"quiz":     "quizzes",
+	}
+	for input, expected := range tests {
+		if got := Pluralize(input); got != expected {
+			t.Errorf("Expected Pluralize(%q) to be '%s', got '%s'", input, expected, got)
+		}
+	}
+}
+
+func TestSnakeCaseNaming_TableName(t *testing.T) {
+	tests := []struct {
+		naming   SnakeCaseNaming
+		typeName string
+		expected string
+	}{
+		{SnakeCaseNaming{}, "Category", "categories"},
+		{SnakeCaseNaming{}, "UserProfile", "user_profiles"},
+		{SnakeCaseNaming{}, "Person", "people"},
+		{SnakeCaseNaming{Singular: true}, "UserProfile", "user_profile"},
+		{SnakeCaseNaming{Prefix: "app_"}, "User", "app_users"},
+		{SnakeCaseNaming{Prefix: "app_", Schema: "chat"}, "Message", "chat.app_messages"},
+	}
+	for _, tt := range tests {
+		if got := tt.naming.TableName(tt.typeName); got != tt.expected {
+			t.Errorf("Expected %+v to name %s '%s', got '%s'", tt.naming, tt.typeName, tt.expected, got)
+		}
+	}
+}
+
+func TestGetTableName_Overrides(t *testing.T) {
+	tests := []struct {
+		model    any
+		expected string
+	}{
+		{CustomTable{}, "legacy_custom"},
+		{PointerTable{}, "pointer_tables"},
+		{TaggedTable{}, "tagged"},
+		{Category{}, "categorys"},
+	}
+	for _, tt := range tests {
+		typ := reflect.TypeOf(tt.model)
+		if got := getTableName(&typ, DefaultNamingStrategy); got != tt.expected {
+			t.Errorf("Expected table name '%s', got '%s'", tt.expected, got)
+		}
+	}
+}
+
+func TestDBRegistry_SetNamingStrategy(t *testing.T) {
+	t.Parallel()
+
+	reg := New()
+	reg.SetNamingStrategy(SnakeCaseNaming{})
+	reg.Register(UserProfile{})
+	reg.Register(Category{})
+	reg.Register(CustomTable{})
+
+	if name := reg.GetTableMeta(UserProfile{}).TableName; name != "user_profiles" {
+		t.Errorf("Expected table name 'user_profiles', got '%s'", name)
+	}
+	if name := reg.GetTableMeta(&Category{}).TableName; name != "categories" {
+		t.Errorf("Expected table name 'categories', got '%s'", name)
+	}
+	if name := reg.GetTableMeta(CustomTable{}).TableName; name != "legacy_custom" {
+		t.Errorf("Expected table name 'legacy_custom', got '%s'", name)
+	}
+}
@@ -1,8 +1,10 @@
 package registry
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -13,75 +15,473 @@ var (
 )
 
 type DBRegistry struct {
-	mu    sync.RWMutex
-	cache map[string]TableMeta
+	mu sync.RWMutex
+	// cache holds the metadata of every registered model type
+	cache map[reflect.Type]TableMeta
+	// tables maps table names to the model type registered for them
+	tables      map[string]reflect.Type
+	naming      NamingStrategy
+	mapUntagged bool
+	// autoRegister makes GetTableMeta register unknown models on first use
+	autoRegister bool
+	// pending holds the auto-registrations in progress, so each type is registered once
+	pending map[reflect.Type]*pendingRegistration
 }
 
+type pendingRegistration struct {
+	done chan struct{}
+	err  error
+}
+
+// New creates an empty registry, independent of the default one returned by GetDBRegistry.
+// Use one per database, or per test so tests can run in parallel.
+func New() *DBRegistry {
+	return &DBRegistry{
+		cache:   make(map[reflect.Type]TableMeta),
+		tables:  make(map[string]reflect.Type),
+		naming:  DefaultNamingStrategy,
+		pending: make(map[reflect.Type]*pendingRegistration),
+	}
+}
+
+// GetDBRegistry returns the default registry, used by builders not given one
 func GetDBRegistry() *DBRegistry {
 	once.Do(func() {
-		instance = &DBRegistry{cache: make(map[string]TableMeta)}
+		instance = New()
 	})
 	return instance
 }
 
-func (r *DBRegistry) GetTableMeta(model any) TableMeta {
-	// get table type
+// SetNamingStrategy sets how table names are derived for models registered afterwards
+func (r *DBRegistry) SetNamingStrategy(naming NamingStrategy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.naming = naming
+}
+
+// MapUntaggedFields makes models registered afterwards also map their exported fields
+// without a db tag, named by the NamingStrategy (CreatedAt -> created_at).
+// Fields tagged db:"-" are always skipped.
+func (r *DBRegistry) MapUntaggedFields(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mapUntagged = enabled
+}
+
+// AutoRegister makes GetTableMeta register struct types that aren't registered yet on
+// first use, instead of panicking. Invalid models still panic on first use;
+// MustRegister checks them at startup.
+func (r *DBRegistry) AutoRegister(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.autoRegister = enabled
+}
+
+func (r *DBRegistry) namingStrategy() NamingStrategy {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.naming
+}
+
+func (r *DBRegistry) columnOptions() columnOptions {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return columnOptions{naming: r.naming, mapUntagged: r.mapUntagged}
+}
+
+// modelType returns the struct type of model, dereferencing pointers
+func modelType(model any) reflect.Type {
 	t := reflect.TypeOf(model)
-	if t.Kind() == reflect.Ptr {
+	if t != nil && t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
+	return t
+}
 
-	tableName := getTableName(&t)
+// typeName returns the package-qualified name of t, telling apart same-named types
+func typeName(t reflect.Type) string {
+	if t == nil {
+		return "<nil>"
+	}
+	if t.PkgPath() == "" {
+		return t.String()
+	}
+	return t.PkgPath() + "." + t.Name()
+}
 
-	tableMeta, ok := r.cache[tableName]
-	if !ok {
-		panic(fmt.Sprintf("Table %s is not registered", tableName))
+// GetTableMeta returns the metadata of a registered model.
+// It panics when the model type is not registered, unless AutoRegister is enabled.
+func (r *DBRegistry) GetTableMeta(model any) TableMeta {
+	tableMeta, err := r.LookupTableMeta(model)
+	if err != nil {
+		panic(err.Error())
 	}
 	return tableMeta
+}
 
+// LookupTableMeta is GetTableMeta returning an error instead of panicking, for models
+// coming from untrusted input
+func (r *DBRegistry) LookupTableMeta(model any) (TableMeta, error) {
+	t := modelType(model)
+
+	r.mu.RLock()
+	tableMeta, ok := r.cache[t]
+	autoRegister := r.autoRegister
+	r.mu.RUnlock()
+	if ok {
+		return tableMeta, nil
+	}
+	if !autoRegister || t == nil || t.Kind() != reflect.Struct {
+		return TableMeta{}, fmt.Errorf("Type %s is not registered", typeName(t))
+	}
+
+	if err := r.registerOnce(t); err != nil {
+		return TableMeta{}, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cache[t], nil
 }
 
-func (r *DBRegistry) Register(model any) {
-	// get table type
-	t := reflect.TypeOf(model)
-	if t.Kind() == reflect.Ptr {
-		t = t.Elem()
+// registerOnce registers t unless it already is. Concurrent callers for the same
+// type wait for a single registration and share its result; a panic during the
+// registration (e.g. in a TableName method) becomes that result.
+func (r *DBRegistry) registerOnce(t reflect.Type) (err error) {
+	r.mu.Lock()
+	if _, ok := r.cache[t]; ok {
+		r.mu.Unlock()
+		return nil
+	}
+	if p, ok := r.pending[t]; ok {
+		r.mu.Unlock()
+		<-p.done
+		return p.err
+	}
+	p := &pendingRegistration{done: make(chan struct{})}
+	r.pending[t] = p
+	r.mu.Unlock()
+
+	defer func() {
+		if v := recover(); v != nil {
+			p.err = fmt.Errorf("register %s: %v", typeName(t), v)
+		}
+		r.mu.Lock()
+		delete(r.pending, t)
+		r.mu.Unlock()
+		close(p.done)
+		err = p.err
+	}()
+	p.err = r.registerType(t)
+	return p.err
+}
+
+// TableMetaByName returns the metadata of the model registered for a table
+func (r *DBRegistry) TableMetaByName(tableName string) (TableMeta, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.tables[tableName]
+	if !ok {
+		return TableMeta{}, false
+	}
+	return r.cache[t], true
+}
+
+// Register adds the table metadata of model to the registry; registering a type again
+// replaces its metadata. It fails when a db tag is invalid or when another type is
+// already registered for the same table. Relations may target models registered later,
+// so Register doesn't report missing targets: call ValidateRelations once every model
+// is registered, or register them together with MustRegister.
+func (r *DBRegistry) Register(model any) error {
+	return r.registerType(modelType(model))
+}
+
+// MustRegister registers every model and panics with all registration errors at once,
+// for validating the models at startup. Relations must target registered models.
+func (r *DBRegistry) MustRegister(models ...any) {
+	var errs []error
+	for _, model := range models {
+		if err := r.Register(model); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	// Relations to models that failed to register would only repeat those errors
+	if len(errs) == 0 {
+		errs = append(errs, r.ValidateRelations())
+	}
+	if err := errors.Join(errs...); err != nil {
+		panic(err)
+	}
+}
+
+func (r *DBRegistry) registerType(t reflect.Type) error {
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("register %s: model must be a struct or a pointer to one", typeName(t))
+	}
+
+	opts := r.columnOptions()
+	tableName := getTableName(&t, opts.naming)
+	tableCols, err := getTableColumns(&t, opts)
+	if err != nil {
+		return fmt.Errorf("register %s: %w", typeName(t), err)
 	}
 
-	tableName := getTableName(&t)
-	tableCols := getTableColsNameMap(&t)
+	tableMeta := newTableMeta(tableName, tableCols)
+	if tableMeta.Relations, err = getTableRelations(t, tableMeta, opts.naming); err != nil {
+		return fmt.Errorf("register %s: %w", typeName(t), err)
+	}
 
-	tableMeta := TableMeta{TableName: tableName, Columns: tableCols}
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	// Store using tableName (e.g., "users") not t.Name() (e.g., "User")
-	r.cache[tableName] = tableMeta
+	if other, ok := r.tables[tableName]; ok && other != t {
+		return fmt.Errorf("register %s: table %s is already registered by %s", typeName(t), tableName, typeName(other))
+	}
+	updated, err := r.resolveRelations(t, tableMeta)
+	if err != nil {
+		return fmt.Errorf("register %s: %w", typeName(t), err)
+	}
+	if previous, ok := r.cache[t]; ok {
+		delete(r.tables, previous.TableName)
+	}
+	for owner, ownerMeta := range updated {
+		r.cache[owner] = ownerMeta
+	}
+	r.tables[tableName] = t
+	return nil
 }
 
-func getTableName(t *reflect.Type) string {
-	return strings.ToLower((*t).Name()) + "s"
+// resolveRelations checks the relations between t, about to be registered with tableMeta,
+// and the registered models: those of t whose target is registered, and those of other
+// models targeting t. It returns the metadata to store, with resolved relations, by type.
+// r.mu must be held.
+func (r *DBRegistry) resolveRelations(t reflect.Type, tableMeta TableMeta) (map[reflect.Type]TableMeta, error) {
+	metas := map[reflect.Type]TableMeta{t: tableMeta}
+	lookup := func(typ reflect.Type) (TableMeta, bool) {
+		if typ == t {
+			return tableMeta, true
+		}
+		meta, ok := r.cache[typ]
+		return meta, ok
+	}
+
+	owners := []reflect.Type{t}
+	for owner, meta := range r.cache {
+		if owner == t {
+			continue
+		}
+		for _, rel := range meta.Relations {
+			if rel.Target == t {
+				owners = append(owners, owner)
+				break
+			}
+		}
+	}
+
+	for _, owner := range owners {
+		ownerMeta, _ := lookup(owner)
+		relations := make(map[string]Relation, len(ownerMeta.Relations))
+		for name, rel := range ownerMeta.Relations {
+			if targetMeta, ok := lookup(rel.Target); ok && (owner == t || rel.Target == t) {
+				resolved, err := resolveRelation(owner, ownerMeta, rel, targetMeta)
+				if err != nil {
+					return nil, fmt.Errorf("relation %s.%s: %w", owner.Name(), name, err)
+				}
+				rel = resolved
+			}
+			relations[name] = rel
+		}
+		ownerMeta.Relations = relations
+		metas[owner] = ownerMeta
+	}
+	return metas, nil
 }
 
-// return map off cols name
-func getTableColsNameMap(t *reflect.Type) map[string]ColumnMeta {
-	colsMap := make(map[string]ColumnMeta, (*t).NumField())
-	for i := 0; i < (*t).NumField(); i++ {
-		f := (*t).Field(i)
-		dbTag := f.Tag.Get("db")
-		if dbTag == "" {
+// ValidateRelations reports the relations whose target model is not registered.
+// It is the required last step of registering models one by one with Register.
+func (r *DBRegistry) ValidateRelations() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var errs []error
+	for owner, meta := range r.cache {
+		for name, rel := range meta.Relations {
+			if _, ok := r.cache[rel.Target]; !ok {
+				errs = append(errs, fmt.Errorf("relation %s.%s: target %s is not registered", typeName(owner), name, typeName(rel.Target)))
+			}
+		}
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errors.Join(errs...)
+}
+
+// columnOptions controls how struct fields are mapped to columns
+type columnOptions struct {
+	naming      NamingStrategy
+	mapUntagged bool
+}
+
+// getTableColumns returns the columns of a model type in field declaration order.
+// Anonymous embedded structs are flattened, as are struct fields tagged inline.
+func getTableColumns(t *reflect.Type, opts columnOptions) ([]ColumnMeta, error) {
+	if opts.naming == nil {
+		opts.naming = DefaultNamingStrategy
+	}
+	c := &columnCollector{opts: opts, visiting: map[reflect.Type]bool{}}
+	if err := c.collect(*t, nil, "", "", "", 0); err != nil {
+		return nil, err
+	}
+
+	// A shallower field shadows deeper ones with the same name, as in Go
+	depth := make(map[string]int)
+	for _, cand := range c.fields {
+		if d, ok := depth[cand.col.Name]; !ok || cand.depth < d {
+			depth[cand.col.Name] = cand.depth
+		}
+	}
+
+	cols := make([]ColumnMeta, 0, len(c.fields))
+	byName := make(map[string]string)
+	byColumn := make(map[string]string)
+	primaryKey := ""
+	for _, cand := range c.fields {
+		col := cand.col
+		if cand.depth > depth[col.Name] {
 			continue
 		}
-		colsMap[f.Name] = ColumnMeta{
-			DBTag: dbTag,
-			Name:  f.Name,
+		if other, ok := byName[col.Name]; ok {
+			return nil, fmt.Errorf("field %s is ambiguous between %s and %s", col.Name, other, col.Path)
+		}
+		if other, ok := byColumn[col.DBTag]; ok {
+			return nil, fmt.Errorf("fields %s and %s both map to column %s", other, col.Path, col.DBTag)
+		}
+		byName[col.Name] = col.Path
+		byColumn[col.DBTag] = col.Path
+
+		if col.PrimaryKey {
+			if primaryKey != "" {
+				return nil, fmt.Errorf("fields %s and %s are both tagged pk; composite primary keys are not supported", primaryKey, col.Path)
+			}
+			primaryKey = col.Path
+		}
+		cols = append(cols, col)
+	}
+	return cols, nil
+}
+
+type columnCandidate struct {
+	col   ColumnMeta
+	depth int
+}
+
+type columnCollector struct {
+	opts     columnOptions
+	fields   []columnCandidate
+	visiting map[reflect.Type]bool
+}
+
+// collect walks the fields of t. index and path locate t in the model, name prefixes the
+// Go names of inline fields and colPrefix their column names; depth counts embedding levels.
+func (c *columnCollector) collect(t reflect.Type, index []int, path, name, colPrefix string, depth int) error {
+	if c.visiting[t] {
+		return fmt.Errorf("struct %s embeds itself", t)
+	}
+	c.visiting[t] = true
+	defer delete(c.visiting, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fieldIndex := append(append([]int(nil), index...), i)
+		fieldPath := path + f.Name
+		if _, ok := f.Tag.Lookup("rel"); ok {
+			// Relations are not columns; they are parsed by getTableRelations
+			if path != "" {
+				return fmt.Errorf("field %s: relations must be declared on the model itself", fieldPath)
+			}
+			continue
+		}
+		dbTag, tagged := f.Tag.Lookup("db")
+		if dbTag == "-" {
+			continue
+		}
+
+		parts := splitTag(dbTag)
+		if inline, prefix, err := inlineOptions(parts); err != nil {
+			return fmt.Errorf("field %s: %w", fieldPath, err)
+		} else if inline || (f.Anonymous && !tagged) {
+			st := f.Type
+			if st.Kind() == reflect.Pointer {
+				st = st.Elem()
+			}
+			if st.Kind() != reflect.Struct {
+				if inline {
+					return fmt.Errorf("field %s: inline requires a struct, got %s", fieldPath, f.Type)
+				}
+				continue
+			}
+			if f.Anonymous && !inline {
+				// Embedded fields are promoted, keeping their Go names
+				if err := c.collect(st, fieldIndex, fieldPath+".", name, colPrefix, depth+1); err != nil {
+					return err
+				}
+				continue
+			}
+			if prefix == "" && parts[0] != "" {
+				prefix = parts[0] + "_"
+			}
+			if err := c.collect(st, fieldIndex, fieldPath+".", name+f.Name+".", colPrefix+prefix, depth); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !tagged || dbTag == "" {
+			if !c.opts.mapUntagged || !f.IsExported() {
+				continue
+			}
+		}
+
+		col := ColumnMeta{
+			Name:  name + f.Name,
 			Type:  f.Type.String(),
 			Tag:   string(f.Tag),
+			Path:  fieldPath,
+			Index: fieldIndex,
 		}
+		if err := parseDBTag(dbTag, f.Type, &col); err != nil {
+			return fmt.Errorf("field %s: %w", fieldPath, err)
+		}
+		if col.DBTag == "" {
+			col.DBTag = c.opts.naming.ColumnName(f.Name)
+		}
+		col.DBTag = colPrefix + col.DBTag
+		c.fields = append(c.fields, columnCandidate{col: col, depth: depth})
+	}
+	return nil
+}
+
+// inlineOptions reports whether the tag parts mark an inline struct and its column prefix
+func inlineOptions(parts []string) (inline bool, prefix string, err error) {
+	others := 0
+	for _, opt := range parts[1:] {
+		switch {
+		case opt == "inline":
+			inline = true
+		case strings.HasPrefix(opt, "prefix="):
+			prefix = strings.TrimPrefix(opt, "prefix=")
+		default:
+			others++
+		}
+	}
+	switch {
+	case prefix != "" && !inline:
+		return false, "", fmt.Errorf("prefix requires inline")
+	case inline && others > 0:
+		return false, "", fmt.Errorf("inline fields take no column options")
 	}
-	return colsMap
+	return inline, prefix, nil
 }
 
-// ResetForTesting resets the registry singleton for testing purposes
+// ResetForTesting resets the registry singleton for testing purposes.
+// It must not run concurrently with other users of the default registry;
+// tests should prefer their own registry from New.
 func ResetForTesting() {
 	instance = nil
 	once = sync.Once{}
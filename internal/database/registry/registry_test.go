@@ -1,9 +1,13 @@
 package registry
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 // TestModel for testing purposes
@@ -33,6 +37,15 @@ type ModelWithPartialTags struct {
 	Age  int    `db:"age"`
 }
 
+// getTableColsNameMap returns the columns of a model type keyed by Go field name
+func getTableColsNameMap(typ *reflect.Type) (map[string]ColumnMeta, error) {
+	cols, err := getTableColumns(typ, columnOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return newTableMeta("", cols).Columns, nil
+}
+
 // resetRegistry resets the singleton instance for testing
 func resetRegistry() {
 	instance = nil
@@ -72,17 +85,32 @@ func TestGetDBRegistry_Initialization(t *testing.T) {
 	}
 }
 
+func TestNew_Independent(t *testing.T) {
+	t.Parallel()
+
+	reg1 := New()
+	reg2 := New()
+	if reg1 == reg2 {
+		t.Fatal("Expected New to return distinct registries")
+	}
+
+	reg1.Register(TestModel{})
+	if _, ok := reg2.TableMetaByName("testmodels"); ok {
+		t.Error("Expected registering in one registry not to affect another")
+	}
+}
+
 func TestDBRegistry_Register(t *testing.T) {
-	resetRegistry()
+	t.Parallel()
 
-	reg := GetDBRegistry()
+	reg := New()
 	reg.Register(TestModel{})
 
 	if len(reg.cache) != 1 {
 		t.Errorf("Expected 1 table in cache, got %d", len(reg.cache))
 	}
 
-	tableMeta, ok := reg.cache["testmodels"]
+	tableMeta, ok := reg.TableMetaByName("testmodels")
 	if !ok {
 		t.Fatal("Expected testmodels to be registered in cache")
 	}
@@ -122,12 +150,12 @@ func TestDBRegistry_Register(t *testing.T) {
 }
 
 func TestDBRegistry_Register_Pointer(t *testing.T) {
-	resetRegistry()
+	t.Parallel()
 
-	reg := GetDBRegistry()
+	reg := New()
 	reg.Register(&TestModel{})
 
-	tableMeta, ok := reg.cache["testmodels"]
+	tableMeta, ok := reg.TableMetaByName("testmodels")
 	if !ok {
 		t.Fatal("Expected testmodels to be registered when passed as pointer")
 	}
@@ -138,9 +166,9 @@ func TestDBRegistry_Register_Pointer(t *testing.T) {
 }
 
 func TestDBRegistry_Register_MultipleModels(t *testing.T) {
-	resetRegistry()
+	t.Parallel()
 
-	reg := GetDBRegistry()
+	reg := New()
 	reg.Register(TestModel{})
 	reg.Register(AnotherTestModel{})
 
@@ -148,24 +176,24 @@ func TestDBRegistry_Register_MultipleModels(t *testing.T) {
 		t.Errorf("Expected 2 tables in cache, got %d", len(reg.cache))
 	}
 
-	_, ok1 := reg.cache["testmodels"]
+	_, ok1 := reg.TableMetaByName("testmodels")
 	if !ok1 {
 		t.Error("Expected testmodels to be registered")
 	}
 
-	_, ok2 := reg.cache["anothertestmodels"]
+	_, ok2 := reg.TableMetaByName("anothertestmodels")
 	if !ok2 {
 		t.Error("Expected anothertestmodels to be registered")
 	}
 }
 
 func TestDBRegistry_Register_WithoutTags(t *testing.T) {
-	resetRegistry()
+	t.Parallel()
 
-	reg := GetDBRegistry()
+	reg := New()
 	reg.Register(ModelWithoutTags{})
 
-	tableMeta, ok := reg.cache["modelwithouttagss"]
+	tableMeta, ok := reg.TableMetaByName("modelwithouttagss")
 	if !ok {
 		t.Fatal("Expected modelwithouttagss to be registered")
 	}
@@ -177,12 +205,12 @@ func TestDBRegistry_Register_WithoutTags(t *testing.T) {
 }
 
 func TestDBRegistry_Register_PartialTags(t *testing.T) {
-	resetRegistry()
+	t.Parallel()
 
-	reg := GetDBRegistry()
+	reg := New()
 	reg.Register(ModelWithPartialTags{})
 
-	tableMeta, ok := reg.cache["modelwithpartialtagss"]
+	tableMeta, ok := reg.TableMetaByName("modelwithpartialtagss")
 	if !ok {
 		t.Fatal("Expected modelwithpartialtagss to be registered")
 	}
@@ -209,9 +237,9 @@ func TestDBRegistry_Register_PartialTags(t *testing.T) {
 }
 
 func TestDBRegistry_GetTableMeta(t *testing.T) {
-	resetRegistry()
+	t.Parallel()
 
-	reg := GetDBRegistry()
+	reg := New()
 	reg.Register(TestModel{})
 
 	tableMeta := reg.GetTableMeta(TestModel{})
@@ -226,9 +254,9 @@ func TestDBRegistry_GetTableMeta(t *testing.T) {
 }
 
 func TestDBRegistry_GetTableMeta_Pointer(t *testing.T) {
-	resetRegistry()
+	t.Parallel()
 
-	reg := GetDBRegistry()
+	reg := New()
 	reg.Register(TestModel{})
 
 	tableMeta := reg.GetTableMeta(&TestModel{})
@@ -239,9 +267,9 @@ func TestDBRegistry_GetTableMeta_Pointer(t *testing.T) {
 }
 
 func TestDBRegistry_GetTableMeta_NotRegistered(t *testing.T) {
-	resetRegistry()
+	t.Parallel()
 
-	reg := GetDBRegistry()
+	reg := New()
 
 	defer func() {
 		if r := recover(); r == nil {
@@ -252,10 +280,25 @@ func TestDBRegistry_GetTableMeta_NotRegistered(t *testing.T) {
 	reg.GetTableMeta(TestModel{})
 }
 
+func TestDBRegistry_LookupTableMeta(t *testing.T) {
+	t.Parallel()
+
+	reg := New()
+	if _, err := reg.LookupTableMeta(TestModel{}); err == nil || !strings.Contains(err.Error(), "is not registered") {
+		t.Errorf("Expected an unregistered model error, got %v", err)
+	}
+
+	reg.Register(TestModel{})
+	tableMeta, err := reg.LookupTableMeta(&TestModel{})
+	if err != nil || tableMeta.TableName != "testmodels" {
+		t.Errorf("Expected the metadata of testmodels, got %+v, %v", tableMeta, err)
+	}
+}
+
 func TestDBRegistry_ThreadSafety(t *testing.T) {
-	resetRegistry()
+	t.Parallel()
 
-	reg := GetDBRegistry()
+	reg := New()
 	var wg sync.WaitGroup
 
 	// Register multiple models concurrently
@@ -322,7 +365,7 @@ func TestGetTableName(t *testing.T) {
 			}
 
 			typ := reflect.TypeOf(model)
-			result := getTableName(&typ)
+			result := getTableName(&typ, DefaultNamingStrategy)
 
 			if result != tt.expected {
 				t.Errorf("Expected table name '%s', got '%s'", tt.expected, result)
@@ -333,7 +376,10 @@ func TestGetTableName(t *testing.T) {
 
 func TestGetTableColsNameMap(t *testing.T) {
 	typ := reflect.TypeOf(TestModel{})
-	cols := getTableColsNameMap(&typ)
+	cols, err := getTableColsNameMap(&typ)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
 
 	if len(cols) != 4 {
 		t.Errorf("Expected 4 columns, got %d", len(cols))
@@ -378,7 +424,10 @@ func TestGetTableColsNameMap(t *testing.T) {
 
 func TestGetTableColsNameMap_IgnoreFieldsWithoutTags(t *testing.T) {
 	typ := reflect.TypeOf(ModelWithPartialTags{})
-	cols := getTableColsNameMap(&typ)
+	cols, err := getTableColsNameMap(&typ)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
 
 	// Should only have 2 columns (ID and Age with db tags)
 	if len(cols) != 2 {
@@ -403,7 +452,10 @@ func TestGetTableColsNameMap_IgnoreFieldsWithoutTags(t *testing.T) {
 
 func TestColumnMeta_Fields(t *testing.T) {
 	typ := reflect.TypeOf(TestModel{})
-	cols := getTableColsNameMap(&typ)
+	cols, err := getTableColsNameMap(&typ)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
 
 	emailCol := cols["Email"]
 
@@ -432,9 +484,9 @@ func TestColumnMeta_Fields(t *testing.T) {
 }
 
 func TestTableMeta_Fields(t *testing.T) {
-	resetRegistry()
+	t.Parallel()
 
-	reg := GetDBRegistry()
+	reg := New()
 	reg.Register(TestModel{})
 
 	tableMeta := reg.GetTableMeta(TestModel{})
@@ -470,3 +522,428 @@ func containsMiddle(s, substr string) bool {
 	}
 	return false
 }
+
+// ModelWithImplicitColumns mixes tagged, untagged and skipped fields
+type ModelWithImplicitColumns struct {
+	ID        int    `db:"id"`
+	CreatedAt string // mapped to created_at when untagged fields are mapped
+	UserID    int
+	Secret    string `db:"-"`
+	internal  string
+}
+
+func TestDBRegistry_MapUntaggedFields(t *testing.T) {
+	t.Parallel()
+
+	reg := New()
+	reg.MapUntaggedFields(true)
+	reg.Register(ModelWithImplicitColumns{})
+
+	tableMeta := reg.GetTableMeta(ModelWithImplicitColumns{})
+	expected := map[string]string{"ID": "id", "CreatedAt": "created_at", "UserID": "user_id"}
+	if len(tableMeta.Columns) != len(expected) {
+		t.Errorf("Expected %d columns, got %d: %v", len(expected), len(tableMeta.Columns), tableMeta.Columns)
+	}
+	for field, column := range expected {
+		if col := tableMeta.Columns[field]; col.DBTag != column {
+			t.Errorf("Expected %s to map to '%s', got '%s'", field, column, col.DBTag)
+		}
+	}
+}
+
+func TestDBRegistry_UntaggedFieldsSkippedByDefault(t *testing.T) {
+	t.Parallel()
+
+	reg := New()
+	reg.Register(ModelWithImplicitColumns{})
+
+	tableMeta := reg.GetTableMeta(ModelWithImplicitColumns{})
+	if len(tableMeta.Columns) != 1 || !tableMeta.HasColumn("ID") {
+		t.Errorf("Expected only the ID column, got %v", tableMeta.Columns)
+	}
+}
+
+func TestTableMeta_OrderedColumns(t *testing.T) {
+	t.Parallel()
+
+	reg := New()
+	reg.Register(TestModel{})
+	tableMeta := reg.GetTableMeta(TestModel{})
+
+	expected := []string{"id", "name", "email", "age"}
+	names := tableMeta.ColumnNames()
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected columns %v in declaration order, got %v", expected, names)
+	}
+	for i, col := range tableMeta.OrderedColumns {
+		if !reflect.DeepEqual(tableMeta.Columns[col.Name], col) {
+			t.Errorf("Expected ordered column %d to match the Columns map, got %+v", i, col)
+		}
+	}
+
+	if col, ok := tableMeta.Column("Email"); !ok || col.DBTag != "email" {
+		t.Errorf("Expected lookup by Go name to find email, got %+v", col)
+	}
+	if col, ok := tableMeta.ColumnByDBTag("age"); !ok || col.Name != "Age" {
+		t.Errorf("Expected lookup by db tag to find Age, got %+v", col)
+	}
+	if _, ok := tableMeta.ColumnByDBTag("Age"); ok {
+		t.Error("Expected lookup by db tag not to match Go names")
+	}
+}
+
+type Timestamps struct {
+	CreatedAt string `db:"created_at,readonly"`
+	UpdatedAt string `db:"updated_at"`
+}
+
+type Audit struct {
+	CreatedBy int `db:"created_by"`
+	Timestamps
+}
+
+type Address struct {
+	Street string `db:"street"`
+	City   string `db:"city"`
+}
+
+// ModelWithEmbedded shares columns through embedded and inline structs
+type ModelWithEmbedded struct {
+	ID int `db:"id,pk"`
+	*Audit
+	Address  Address `db:"address,inline,prefix=addr_"`
+	Shipping Address `db:"shipping,inline"`
+	Billing  Address `db:",inline"`
+	Skipped  Address `db:"-"`
+}
+
+func TestGetTableColumns_Embedded(t *testing.T) {
+	typ := reflect.TypeOf(ModelWithEmbedded{})
+	cols, err := getTableColumns(&typ, columnOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []struct {
+		name, column, path string
+		index              []int
+	}{
+		{"ID", "id", "ID", []int{0}},
+		{"CreatedBy", "created_by", "Audit.CreatedBy", []int{1, 0}},
+		{"CreatedAt", "created_at", "Audit.Timestamps.CreatedAt", []int{1, 1, 0}},
+		{"UpdatedAt", "updated_at", "Audit.Timestamps.UpdatedAt", []int{1, 1, 1}},
+		{"Address.Street", "addr_street", "Address.Street", []int{2, 0}},
+		{"Address.City", "addr_city", "Address.City", []int{2, 1}},
+		{"Shipping.Street", "shipping_street", "Shipping.Street", []int{3, 0}},
+		{"Shipping.City", "shipping_city", "Shipping.City", []int{3, 1}},
+		{"Billing.Street", "street", "Billing.Street", []int{4, 0}},
+		{"Billing.City", "city", "Billing.City", []int{4, 1}},
+	}
+	if len(cols) != len(expected) {
+		t.Fatalf("Expected %d columns, got %d: %+v", len(expected), len(cols), cols)
+	}
+	for i, e := range expected {
+		col := cols[i]
+		if col.Name != e.name || col.DBTag != e.column || col.Path != e.path || !reflect.DeepEqual(col.Index, e.index) {
+			t.Errorf("Expected column %d to be %+v, got name=%s column=%s path=%s index=%v", i, e, col.Name, col.DBTag, col.Path, col.Index)
+		}
+	}
+	if !cols[2].ReadOnly {
+		t.Error("Expected options of embedded fields to be parsed")
+	}
+
+	// Index reaches the field through the embedded pointer
+	model := ModelWithEmbedded{Audit: &Audit{}}
+	reflect.ValueOf(&model).Elem().FieldByIndex(cols[2].Index).SetString("now")
+	if model.CreatedAt != "now" {
+		t.Errorf("Expected CreatedAt to be set through its index, got '%s'", model.CreatedAt)
+	}
+}
+
+func TestGetTableColumns_Shadowing(t *testing.T) {
+	type model struct {
+		Timestamps
+		CreatedAt string `db:"created"`
+	}
+	typ := reflect.TypeOf(model{})
+	cols, err := getTableColumns(&typ, columnOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(cols) != 2 || cols[0].DBTag != "updated_at" || cols[1].DBTag != "created" {
+		t.Errorf("Expected the outer CreatedAt to shadow the embedded one, got %+v", cols)
+	}
+}
+
+func TestGetTableColumns_Conflicts(t *testing.T) {
+	type other struct {
+		CreatedAt string `db:"created"`
+	}
+	type duplicateColumn struct {
+		Created string `db:"created_at"`
+		Timestamps
+	}
+	type ambiguous struct {
+		Timestamps
+		other
+	}
+	type badInline struct {
+		Name string `db:"name,inline"`
+	}
+	type inlineOptions struct {
+		Address Address `db:"address,inline,unique"`
+	}
+	type prefixWithoutInline struct {
+		Name string `db:"name,prefix=x_"`
+	}
+
+	tests := []struct {
+		model    any
+		expected string
+	}{
+		{duplicateColumn{}, "both map to column created_at"},
+		{ambiguous{}, "ambiguous"},
+		{badInline{}, "inline requires a struct"},
+		{inlineOptions{}, "no column options"},
+		{prefixWithoutInline{}, "prefix requires inline"},
+	}
+	for _, tt := range tests {
+		typ := reflect.TypeOf(tt.model)
+		_, err := getTableColumns(&typ, columnOptions{})
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("Expected error containing '%s' for %s, got %v", tt.expected, typ, err)
+		}
+	}
+}
+
+type selfEmbedding struct {
+	*selfEmbedding
+	ID int `db:"id"`
+}
+
+func TestGetTableColumns_SelfEmbedding(t *testing.T) {
+	typ := reflect.TypeOf(selfEmbedding{})
+	if _, err := getTableColumns(&typ, columnOptions{}); err == nil {
+		t.Error("Expected error for a struct embedding itself")
+	}
+}
+
+func TestDBRegistry_Register_TableCollision(t *testing.T) {
+	t.Parallel()
+
+	reg := New()
+	if err := reg.Register(TestModel{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Same type name from another scope, deriving the same table name
+	type TestModel struct {
+		ID int `db:"id"`
+	}
+	err := reg.Register(TestModel{})
+	if err == nil {
+		t.Fatal("Expected error for two types registered for the same table")
+	}
+	if !strings.Contains(err.Error(), "table testmodels is already registered") {
+		t.Errorf("Expected collision error, got %v", err)
+	}
+
+	// The original registration is kept
+	if tableMeta, _ := reg.TableMetaByName("testmodels"); len(tableMeta.Columns) != 4 {
+		t.Errorf("Expected the original 4 columns, got %d", len(tableMeta.Columns))
+	}
+}
+
+func TestDBRegistry_Register_SameTypeTwice(t *testing.T) {
+	t.Parallel()
+
+	reg := New()
+	if err := reg.Register(TestModel{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := reg.Register(&TestModel{}); err != nil {
+		t.Errorf("Expected registering the same type again to succeed, got %v", err)
+	}
+	if len(reg.cache) != 1 {
+		t.Errorf("Expected 1 table in cache, got %d", len(reg.cache))
+	}
+}
+
+func TestDBRegistry_Register_RenamedTable(t *testing.T) {
+	t.Parallel()
+
+	reg := New()
+	reg.Register(TestModel{})
+	reg.SetNamingStrategy(SnakeCaseNaming{})
+	reg.Register(TestModel{})
+
+	if _, ok := reg.TableMetaByName("testmodels"); ok {
+		t.Error("Expected the old table name to be unregistered")
+	}
+	if tableMeta, ok := reg.TableMetaByName("test_models"); !ok || tableMeta.TableName != "test_models" {
+		t.Errorf("Expected test_models to be registered, got %v", tableMeta.TableName)
+	}
+}
+
+func TestDBRegistry_ConcurrentAccess(t *testing.T) {
+	t.Parallel()
+
+	reg := New()
+	reg.Register(TestModel{})
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			reg.Register(AnotherTestModel{})
+		}()
+		go func() {
+			defer wg.Done()
+			reg.GetTableMeta(TestModel{})
+		}()
+		go func() {
+			defer wg.Done()
+			reg.TableMetaByName("anothertestmodels")
+		}()
+		go func() {
+			defer wg.Done()
+			reg.MapUntaggedFields(false)
+		}()
+	}
+	wg.Wait()
+}
+
+var autoRegisteredCalls atomic.Int32
+
+// AutoRegisteredModel counts how often its table name is resolved
+type AutoRegisteredModel struct {
+	ID int `db:"id"`
+}
+
+func (AutoRegisteredModel) TableName() string {
+	autoRegisteredCalls.Add(1)
+	return "auto_registered"
+}
+
+func TestDBRegistry_AutoRegister(t *testing.T) {
+	t.Parallel()
+
+	// The counter outlives the test, e.g. with -count=2
+	autoRegisteredCalls.Store(0)
+	reg := New()
+	reg.AutoRegister(true)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if name := reg.GetTableMeta(&AutoRegisteredModel{}).TableName; name != "auto_registered" {
+				t.Errorf("Expected table name 'auto_registered', got '%s'", name)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls := autoRegisteredCalls.Load(); calls != 1 {
+		t.Errorf("Expected the model to be registered once, got %d registrations", calls)
+	}
+	if _, ok := reg.TableMetaByName("auto_registered"); !ok {
+		t.Error("Expected auto_registered to be registered")
+	}
+}
+
+// PanickingModel panics while its table name is resolved
+type PanickingModel struct {
+	ID int `db:"id"`
+}
+
+func (PanickingModel) TableName() string { panic("no table") }
+
+func TestDBRegistry_AutoRegister_Panic(t *testing.T) {
+	t.Parallel()
+
+	reg := New()
+	reg.AutoRegister(true)
+
+	// Every call fails with the panic instead of waiting for the first registration
+	for i := 0; i < 2; i++ {
+		done := make(chan any)
+		go func() {
+			defer func() { done <- recover() }()
+			reg.GetTableMeta(PanickingModel{})
+		}()
+		select {
+		case r := <-done:
+			if msg, ok := r.(string); !ok || !strings.Contains(msg, "no table") {
+				t.Errorf("Expected a panic with the registration error, got %v", r)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("GetTableMeta blocked after a panicking registration")
+		}
+	}
+
+	if _, err := reg.LookupTableMeta(PanickingModel{}); err == nil || !strings.Contains(err.Error(), "register") {
+		t.Errorf("Expected a registration error, got %v", err)
+	}
+}
+
+func TestDBRegistry_AutoRegister_Disabled(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic for unregistered model without auto-registration")
+		}
+	}()
+	New().GetTableMeta(TestModel{})
+}
+
+func TestDBRegistry_AutoRegister_InvalidModel(t *testing.T) {
+	t.Parallel()
+
+	type invalidModel struct {
+		ID int `db:"id,unknown"`
+	}
+	reg := New()
+	reg.AutoRegister(true)
+
+	defer func() {
+		r := recover()
+		if r == nil || !strings.Contains(fmt.Sprint(r), "invalidModel") {
+			t.Errorf("Expected panic naming the invalid model, got %v", r)
+		}
+	}()
+	reg.GetTableMeta(invalidModel{})
+}
+
+func TestDBRegistry_MustRegister(t *testing.T) {
+	t.Parallel()
+
+	reg := New()
+	reg.Register(TestModel{})
+
+	type badOption struct {
+		ID int `db:"id,unknown"`
+	}
+	type TestModel struct {
+		ID int `db:"id"`
+	}
+
+	defer func() {
+		err, ok := recover().(error)
+		if !ok {
+			t.Fatalf("Expected panic with an error, got %v", err)
+		}
+		for _, expected := range []string{"badOption", "table testmodels is already registered", "must be a struct"} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("Expected error to contain '%s', got:\n%v", expected, err)
+			}
+		}
+		// Valid models are registered regardless
+		if _, ok := reg.TableMetaByName("anothertestmodels"); !ok {
+			t.Error("Expected anothertestmodels to be registered")
+		}
+	}()
+	reg.MustRegister(AnotherTestModel{}, badOption{}, TestModel{}, 42)
+}
@@ -0,0 +1,245 @@
+package registry
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RelationKind is the kind of relationship between two models
+type RelationKind string
+
+const (
+	BelongsTo  RelationKind = "belongs_to"
+	HasOne     RelationKind = "has_one"
+	HasMany    RelationKind = "has_many"
+	ManyToMany RelationKind = "many2many"
+)
+
+// Relation describes a field holding related models, declared with a rel tag, e.g.
+// `rel:"belongs_to,fk=UserID"`, `rel:"has_many,fk=UserID"` or `rel:"many2many,join=user_rooms"`
+type Relation struct {
+	// Name is the Go field holding the related model(s)
+	Name string
+	Kind RelationKind
+	// Target is the struct type of the related model
+	Target reflect.Type
+	// Index is the field index sequence for reflect.Value.FieldByIndex
+	Index []int
+	// ForeignKey is the Go field holding the key: on the model for belongs_to,
+	// on the target for has_one and has_many. It is empty for many2many.
+	ForeignKey string
+	// References is the Go field the key points to: on the target for belongs_to,
+	// on the model otherwise. It defaults to the primary key.
+	References string
+	// JoinTable is the many2many join table, whose JoinForeignKey column references
+	// the model and JoinReferences column the target (e.g. user_rooms, user_id, room_id)
+	JoinTable      string
+	JoinForeignKey string
+	JoinReferences string
+	// TargetKey is the Go field of the target referenced by the join table, its primary key
+	TargetKey string
+}
+
+// Relation returns the relation declared on a Go field
+func (t TableMeta) Relation(fieldName string) (Relation, bool) {
+	rel, ok := t.Relations[fieldName]
+	return rel, ok
+}
+
+// relationOptions lists the rel tag options allowed for each kind
+var relationOptions = map[RelationKind][]string{
+	BelongsTo:  {"fk", "references"},
+	HasOne:     {"fk", "references"},
+	HasMany:    {"fk", "references"},
+	ManyToMany: {"join", "join_fk", "join_ref", "references"},
+}
+
+// getTableRelations parses the rel tags of a model type. Keys on the model itself are
+// checked against tableMeta; keys on the target are checked once it is registered.
+func getTableRelations(t reflect.Type, tableMeta TableMeta, naming NamingStrategy) (map[string]Relation, error) {
+	relations := make(map[string]Relation)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("rel")
+		if !ok {
+			continue
+		}
+		rel, err := parseRelTag(tag, f, t, naming)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.Name, err)
+		}
+
+		// Keys on the model
+		ownerKey := rel.References
+		if rel.Kind == BelongsTo {
+			ownerKey = rel.ForeignKey
+		}
+		if ownerKey == "" {
+			pk, ok := tableMeta.PrimaryKey()
+			if !ok {
+				return nil, fmt.Errorf("field %s: %s relation needs a primary key or references", f.Name, rel.Kind)
+			}
+			rel.References = pk.Name
+			ownerKey = pk.Name
+		}
+		if !tableMeta.HasColumn(ownerKey) {
+			return nil, fmt.Errorf("field %s: key %s is not a column of %s", f.Name, ownerKey, t.Name())
+		}
+		relations[f.Name] = rel
+	}
+	return relations, nil
+}
+
+// parseRelTag parses the rel tag of field f of model type owner
+func parseRelTag(tag string, f reflect.StructField, owner reflect.Type, naming NamingStrategy) (Relation, error) {
+	if _, ok := f.Tag.Lookup("db"); ok {
+		return Relation{}, fmt.Errorf("a relation field cannot have a db tag")
+	}
+	parts := splitTag(tag)
+	rel := Relation{Name: f.Name, Kind: RelationKind(parts[0]), Index: f.Index}
+	allowed, ok := relationOptions[rel.Kind]
+	if !ok {
+		return Relation{}, fmt.Errorf("unknown relation kind '%s'", parts[0])
+	}
+
+	for _, opt := range parts[1:] {
+		key, value, _ := strings.Cut(opt, "=")
+		if !containsString(allowed, key) {
+			return Relation{}, fmt.Errorf("invalid option '%s' for %s relation", opt, rel.Kind)
+		}
+		if value == "" {
+			return Relation{}, fmt.Errorf("rel tag option '%s' needs a value", key)
+		}
+		switch key {
+		case "fk":
+			rel.ForeignKey = value
+		case "references":
+			rel.References = value
+		case "join":
+			rel.JoinTable = value
+		case "join_fk":
+			rel.JoinForeignKey = value
+		case "join_ref":
+			rel.JoinReferences = value
+		}
+	}
+
+	// The field holds one target for belongs_to and has_one, a slice of them otherwise
+	target := f.Type
+	many := rel.Kind == HasMany || rel.Kind == ManyToMany
+	if many {
+		if target.Kind() != reflect.Slice {
+			return Relation{}, fmt.Errorf("%s relation requires a slice, got %s", rel.Kind, f.Type)
+		}
+		target = target.Elem()
+	}
+	if target.Kind() == reflect.Pointer {
+		target = target.Elem()
+	}
+	if target.Kind() != reflect.Struct {
+		return Relation{}, fmt.Errorf("%s relation requires a struct target, got %s", rel.Kind, f.Type)
+	}
+	rel.Target = target
+
+	switch rel.Kind {
+	case BelongsTo:
+		if rel.ForeignKey == "" {
+			rel.ForeignKey = f.Name + "ID"
+		}
+	case HasOne, HasMany:
+		if rel.ForeignKey == "" {
+			rel.ForeignKey = owner.Name() + "ID"
+		}
+	case ManyToMany:
+		if rel.JoinTable == "" {
+			return Relation{}, fmt.Errorf("many2many relation requires a join table")
+		}
+		if rel.JoinForeignKey == "" {
+			rel.JoinForeignKey = naming.ColumnName(owner.Name()) + "_id"
+		}
+		if rel.JoinReferences == "" {
+			rel.JoinReferences = naming.ColumnName(target.Name()) + "_id"
+		}
+		if rel.JoinForeignKey == rel.JoinReferences {
+			return Relation{}, fmt.Errorf("join table columns are both named %s; set join_fk and join_ref", rel.JoinForeignKey)
+		}
+	}
+	return rel, nil
+}
+
+// resolveRelation checks rel, declared on owner, against its registered target and fills
+// in the keys defaulting to the target's primary key
+func resolveRelation(owner reflect.Type, ownerMeta TableMeta, rel Relation, targetMeta TableMeta) (Relation, error) {
+	var ownerKey, targetKey string
+	switch rel.Kind {
+	case BelongsTo:
+		if rel.References == "" {
+			pk, ok := targetMeta.PrimaryKey()
+			if !ok {
+				return rel, fmt.Errorf("target %s has no primary key; set references", typeName(rel.Target))
+			}
+			rel.References = pk.Name
+		}
+		ownerKey, targetKey = rel.ForeignKey, rel.References
+	case HasOne, HasMany:
+		ownerKey, targetKey = rel.References, rel.ForeignKey
+	case ManyToMany:
+		pk, ok := targetMeta.PrimaryKey()
+		if !ok {
+			return rel, fmt.Errorf("target %s has no primary key", typeName(rel.Target))
+		}
+		rel.TargetKey = pk.Name
+		return rel, nil
+	}
+
+	targetCol, ok := targetMeta.Column(targetKey)
+	if !ok {
+		return rel, fmt.Errorf("key %s is not a column of %s", targetKey, typeName(rel.Target))
+	}
+	ownerCol := ownerMeta.Columns[ownerKey]
+	ownerType := owner.FieldByIndex(ownerCol.Index).Type
+	targetType := rel.Target.FieldByIndex(targetCol.Index).Type
+	if !compatibleKeyTypes(ownerType, targetType) {
+		return rel, fmt.Errorf("key types %s (%s) and %s (%s) are not compatible", ownerKey, ownerType, targetKey, targetType)
+	}
+	return rel, nil
+}
+
+// compatibleKeyTypes reports whether values of a and b can be compared as keys:
+// the same type, or both integers, or both strings, pointers being dereferenced
+func compatibleKeyTypes(a, b reflect.Type) bool {
+	if a.Kind() == reflect.Pointer {
+		a = a.Elem()
+	}
+	if b.Kind() == reflect.Pointer {
+		b = b.Elem()
+	}
+	switch {
+	case a == b:
+		return true
+	case isIntegerKind(a.Kind()) && isIntegerKind(b.Kind()):
+		return true
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return true
+	}
+	return false
+}
+
+func isIntegerKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
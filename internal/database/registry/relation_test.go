@@ -0,0 +1,231 @@
+package registry
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Author, Post, Profile and Tag relate to each other in every supported way
+type Author struct {
+	ID      int      `db:"id,pk"`
+	Name    string   `db:"name"`
+	Posts   []Post   `rel:"has_many"`
+	Profile *Profile `rel:"has_one"`
+}
+
+type Post struct {
+	ID       int     `db:"id,pk"`
+	AuthorID int64   `db:"author_id"`
+	Author   *Author `rel:"belongs_to"`
+	Tags     []*Tag  `rel:"many2many,join=post_tags"`
+}
+
+type Profile struct {
+	ID       int `db:"id,pk"`
+	AuthorID int `db:"author_id"`
+}
+
+type Tag struct {
+	ID   int    `db:"id,pk"`
+	Name string `db:"name"`
+}
+
+func TestRegister_Relations(t *testing.T) {
+	t.Parallel()
+
+	reg := New()
+	// Registration order doesn't matter; relations resolve once both sides are registered
+	reg.MustRegister(Post{}, Tag{}, Author{}, Profile{})
+
+	author := reg.GetTableMeta(Author{})
+	if _, ok := author.Columns["Posts"]; ok {
+		t.Error("Expected relation fields not to be columns")
+	}
+	expected := map[string]Relation{
+		"Posts":   {Name: "Posts", Kind: HasMany, Target: reflect.TypeOf(Post{}), Index: []int{2}, ForeignKey: "AuthorID", References: "ID"},
+		"Profile": {Name: "Profile", Kind: HasOne, Target: reflect.TypeOf(Profile{}), Index: []int{3}, ForeignKey: "AuthorID", References: "ID"},
+	}
+	if !reflect.DeepEqual(author.Relations, expected) {
+		t.Errorf("Expected relations %+v, got %+v", expected, author.Relations)
+	}
+
+	post := reg.GetTableMeta(Post{})
+	expected = map[string]Relation{
+		"Author": {Name: "Author", Kind: BelongsTo, Target: reflect.TypeOf(Author{}), Index: []int{2}, ForeignKey: "AuthorID", References: "ID"},
+		"Tags": {Name: "Tags", Kind: ManyToMany, Target: reflect.TypeOf(Tag{}), Index: []int{3}, References: "ID",
+			JoinTable: "post_tags", JoinForeignKey: "post_id", JoinReferences: "tag_id", TargetKey: "ID"},
+	}
+	if !reflect.DeepEqual(post.Relations, expected) {
+		t.Errorf("Expected relations %+v, got %+v", expected, post.Relations)
+	}
+}
+
+func TestRegister_RelationTagErrors(t *testing.T) {
+	t.Parallel()
+
+	type unknownKind struct {
+		ID    int    `db:"id,pk"`
+		Posts []Post `rel:"has_lots"`
+	}
+	type missingFK struct {
+		ID     int     `db:"id,pk"`
+		Author *Author `rel:"belongs_to,fk=WriterID"`
+	}
+	type notSlice struct {
+		ID    int  `db:"id,pk"`
+		Posts Post `rel:"has_many"`
+	}
+	type notStruct struct {
+		ID    int   `db:"id,pk"`
+		Posts []int `rel:"has_many"`
+	}
+	type noJoin struct {
+		ID   int   `db:"id,pk"`
+		Tags []Tag `rel:"many2many"`
+	}
+	type sameJoinColumns struct {
+		ID     int               `db:"id,pk"`
+		Others []sameJoinColumns `rel:"many2many,join=links"`
+	}
+	type badOption struct {
+		ID    int    `db:"id,pk"`
+		Posts []Post `rel:"has_many,join=x"`
+	}
+	type withDBTag struct {
+		ID    int    `db:"id,pk"`
+		Posts []Post `db:"posts" rel:"has_many"`
+	}
+	type noPrimaryKey struct {
+		Posts []Post `rel:"has_many,fk=AuthorID"`
+	}
+	type nested struct {
+		Inner struct {
+			Posts []Post `rel:"has_many"`
+		} `db:"inner,inline"`
+	}
+
+	tests := []struct {
+		model    any
+		expected string
+	}{
+		{unknownKind{}, "unknown relation kind 'has_lots'"},
+		{missingFK{}, "key WriterID is not a column"},
+		{notSlice{}, "has_many relation requires a slice"},
+		{notStruct{}, "requires a struct target"},
+		{noJoin{}, "requires a join table"},
+		{sameJoinColumns{}, "set join_fk and join_ref"},
+		{badOption{}, "invalid option 'join=x'"},
+		{withDBTag{}, "cannot have a db tag"},
+		{noPrimaryKey{}, "needs a primary key"},
+		{nested{}, "declared on the model itself"},
+	}
+	for _, tt := range tests {
+		err := New().Register(tt.model)
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("Expected error containing '%s' for %T, got %v", tt.expected, tt.model, err)
+		}
+	}
+}
+
+func TestRegister_RelationTargetErrors(t *testing.T) {
+	t.Parallel()
+
+	type Writer struct {
+		ID    int    `db:"id,pk"`
+		Posts []Post `rel:"has_many,fk=WriterID"`
+	}
+	type Reader struct {
+		ID   string `db:"id,pk"`
+		Post *Post  `rel:"belongs_to,fk=ID"`
+	}
+	type Keyless struct {
+		Name string `db:"name"`
+	}
+	type Owner struct {
+		ID      int      `db:"id,pk"`
+		KeyName string   `db:"key_name"`
+		Keyless *Keyless `rel:"belongs_to,fk=KeyName"`
+	}
+
+	tests := []struct {
+		models   []any
+		expected string
+	}{
+		{[]any{Post{}, Writer{}}, "key WriterID is not a column"},
+		// The target registered after the model still validates the relation
+		{[]any{Writer{}, Post{}}, "key WriterID is not a column"},
+		{[]any{Post{}, Reader{}}, "are not compatible"},
+		{[]any{Keyless{}, Owner{}}, "has no primary key"},
+	}
+	for _, tt := range tests {
+		reg := New()
+		var err error
+		for _, m := range tt.models {
+			if err = reg.Register(m); err != nil {
+				break
+			}
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("Expected error containing '%s' registering %T, got %v", tt.expected, tt.models, err)
+		}
+	}
+}
+
+func TestRegister_RelationTargetFailureKeepsModels(t *testing.T) {
+	t.Parallel()
+
+	type Writer struct {
+		ID    int    `db:"id,pk"`
+		Posts []Post `rel:"has_many,fk=WriterID"`
+	}
+	reg := New()
+	reg.Register(Writer{})
+	if err := reg.Register(Post{}); err == nil {
+		t.Fatal("Expected error for a relation to a missing key")
+	}
+	if _, ok := reg.TableMetaByName("posts"); ok {
+		t.Error("Expected the failing target not to be registered")
+	}
+	if _, ok := reg.GetTableMeta(Writer{}).Relation("Posts"); !ok {
+		t.Error("Expected the model's relation to be kept")
+	}
+}
+
+func TestValidateRelations(t *testing.T) {
+	t.Parallel()
+
+	reg := New()
+	reg.Register(Post{})
+	err := reg.ValidateRelations()
+	if err == nil {
+		t.Fatal("Expected error for relations to unregistered models")
+	}
+	for _, target := range []string{"registry.Author", "registry.Tag"} {
+		if !strings.Contains(err.Error(), "target little-orm/internal/database/"+target+" is not registered") {
+			t.Errorf("Expected error for %s, got %v", target, err)
+		}
+	}
+
+	reg.MustRegister(Author{}, Tag{}, Profile{})
+	if err := reg.ValidateRelations(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestMustRegister_UnregisteredTarget(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		err, ok := recover().(error)
+		if !ok || !strings.Contains(err.Error(), "Profile is not registered") {
+			t.Errorf("Expected panic for the unregistered relation target, got %v", err)
+		}
+		var joined interface{ Unwrap() []error }
+		if !errors.As(err, &joined) {
+			t.Errorf("Expected the errors to be joined, got %T", err)
+		}
+	}()
+	New().MustRegister(Author{}, Post{}, Tag{})
+}
@@ -0,0 +1,78 @@
+package registry
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// parseDBTag parses a db tag such as "id,pk,autoincr" or "created_at,default:now(),readonly"
+// into col. The column name is left empty when the tag only has options (e.g. ",pk").
+func parseDBTag(tag string, fieldType reflect.Type, col *ColumnMeta) error {
+	parts := splitTag(tag)
+	col.DBTag = parts[0]
+
+	for _, opt := range parts[1:] {
+		key, value, hasValue := strings.Cut(opt, ":")
+		if hasValue != (key == "default" || key == "size") {
+			return fmt.Errorf("invalid db tag option '%s'", opt)
+		}
+		switch key {
+		case "pk":
+			col.PrimaryKey = true
+		case "autoincr":
+			col.AutoIncrement = true
+		case "default":
+			if value == "" {
+				return fmt.Errorf("db tag option 'default' needs a value")
+			}
+			col.Default = value
+		case "readonly":
+			col.ReadOnly = true
+		case "nullable":
+			col.Nullable = true
+		case "unique":
+			col.Unique = true
+		case "omitempty":
+			col.OmitEmpty = true
+		case "size":
+			size, err := strconv.Atoi(value)
+			if err != nil || size <= 0 {
+				return fmt.Errorf("invalid size '%s'", value)
+			}
+			col.Size = size
+		default:
+			return fmt.Errorf("unknown db tag option '%s'", key)
+		}
+	}
+
+	if col.AutoIncrement && !isIntegerKind(fieldType.Kind()) {
+		return fmt.Errorf("autoincr requires an integer field, got %s", fieldType)
+	}
+	if col.PrimaryKey && col.Nullable {
+		return fmt.Errorf("a primary key cannot be nullable")
+	}
+	return nil
+}
+
+// splitTag splits a tag on commas that are not inside parentheses, so defaults
+// such as "default:coalesce(a, b)" stay in one piece
+func splitTag(tag string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, ch := range tag {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(tag[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(tag[start:]))
+}
@@ -0,0 +1,104 @@
+package registry
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ModelWithOptions uses every db tag option
+type ModelWithOptions struct {
+	ID        int    `db:"id,pk,autoincr"`
+	Email     string `db:"email,unique,size:255"`
+	Bio       string `db:"bio,nullable,omitempty"`
+	CreatedAt string `db:"created_at,default:now(),readonly"`
+	Score     int    `db:"score,default:coalesce(1, 2)"`
+	Slug      string `db:",unique"`
+}
+
+func TestParseDBTag_Options(t *testing.T) {
+	typ := reflect.TypeOf(ModelWithOptions{})
+	cols, err := getTableColsNameMap(&typ)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	id := cols["ID"]
+	if id.DBTag != "id" || !id.PrimaryKey || !id.AutoIncrement || id.Insertable() {
+		t.Errorf("Unexpected ID column %+v", id)
+	}
+	email := cols["Email"]
+	if email.DBTag != "email" || !email.Unique || email.Size != 255 || !email.Insertable() {
+		t.Errorf("Unexpected Email column %+v", email)
+	}
+	bio := cols["Bio"]
+	if !bio.Nullable || !bio.OmitEmpty {
+		t.Errorf("Unexpected Bio column %+v", bio)
+	}
+	createdAt := cols["CreatedAt"]
+	if createdAt.Default != "now()" || !createdAt.ReadOnly || createdAt.Insertable() {
+		t.Errorf("Unexpected CreatedAt column %+v", createdAt)
+	}
+	if score := cols["Score"]; score.Default != "coalesce(1, 2)" {
+		t.Errorf("Expected default 'coalesce(1, 2)', got '%s'", score.Default)
+	}
+	if slug := cols["Slug"]; slug.DBTag != "slug" || !slug.Unique {
+		t.Errorf("Expected an options-only tag to use the naming strategy, got %+v", slug)
+	}
+}
+
+func TestParseDBTag_Errors(t *testing.T) {
+	tests := []struct {
+		tag      string
+		field    any
+		expected string
+	}{
+		{"id,primary", 0, "unknown db tag option"},
+		{"name,size:abc", "", "invalid size"},
+		{"name,size", "", "invalid db tag option"},
+		{"name,unique:yes", "", "invalid db tag option"},
+		{"name,default:", "", "needs a value"},
+		{"name,autoincr", "", "requires an integer"},
+		{"id,pk,nullable", 0, "cannot be nullable"},
+	}
+	for _, tt := range tests {
+		var col ColumnMeta
+		err := parseDBTag(tt.tag, reflect.TypeOf(tt.field), &col)
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("Expected error containing '%s' for tag %q, got %v", tt.expected, tt.tag, err)
+		}
+	}
+}
+
+func TestTableMeta_PrimaryKey(t *testing.T) {
+	t.Parallel()
+
+	reg := New()
+	if err := reg.Register(ModelWithOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := reg.Register(TestModel{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	pk, ok := reg.GetTableMeta(ModelWithOptions{}).PrimaryKey()
+	if !ok || pk.Name != "ID" {
+		t.Errorf("Expected primary key ID, got %+v", pk)
+	}
+	if _, ok := reg.GetTableMeta(TestModel{}).PrimaryKey(); ok {
+		t.Error("Expected no primary key for a model without a pk tag")
+	}
+}
+
+func TestDBRegistry_Register_InvalidTag(t *testing.T) {
+	t.Parallel()
+
+	type badModel struct {
+		ID   int `db:"id,pk"`
+		Code int `db:"code,pk"`
+	}
+	err := New().Register(badModel{})
+	if err == nil || !strings.Contains(err.Error(), "composite primary keys") {
+		t.Errorf("Expected composite primary key error, got %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+// Code generated by colgen; DO NOT EDIT.
+
+package columns
+
+import (
+	"little-orm/internal/database/querybuilder"
+)
+
+// MessageCols holds typed column handles for Message
+var MessageCols = struct {
+	ID      querybuilder.Column[int]
+	UserID  querybuilder.Column[int]
+	Content querybuilder.Column[string]
+}{
+	ID:      querybuilder.NewColumn[int]("ID"),
+	UserID:  querybuilder.NewColumn[int]("UserID"),
+	Content: querybuilder.NewColumn[string]("Content"),
+}
+
+// UserCols holds typed column handles for User
+var UserCols = struct {
+	ID       querybuilder.Column[int]
+	Email    querybuilder.Column[string]
+	Name     querybuilder.Column[string]
+	Password querybuilder.Column[string]
+}{
+	ID:       querybuilder.NewColumn[int]("ID"),
+	Email:    querybuilder.NewColumn[string]("Email"),
+	Name:     querybuilder.NewColumn[string]("Name"),
+	Password: querybuilder.NewColumn[string]("Password"),
+}
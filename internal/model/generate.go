@@ -0,0 +1,3 @@
+package model
+
+//go:generate go run little-orm/cmd/colgen -out columns/columns_gen.go
@@ -1,6 +1,8 @@
 package model
 
 type Message struct {
-	ID      int    `db:"id"`
+	ID      int    `db:"id,pk,autoincr"`
+	UserID  int    `db:"user_id"`
 	Content string `db:"content"`
+	User    *User  `rel:"belongs_to,fk=UserID"`
 }
@@ -1,8 +1,9 @@
 package model
 
 type User struct {
-	ID       int    `db:"id"`
-	Email    string `db:"email"`
-	Name     string `db:"name"`
-	Password string `db:"password"`
+	ID       int       `db:"id,pk,autoincr"`
+	Email    string    `db:"email"`
+	Name     string    `db:"name"`
+	Password string    `db:"password"`
+	Messages []Message `rel:"has_many,fk=UserID"`
 }